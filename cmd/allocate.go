@@ -0,0 +1,200 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/allocate"
+	"github.com/marstr/baronial/internal/index"
+)
+
+const (
+	allocateRulesFlag      = "rules"
+	allocateRulesShorthand = "r"
+	allocateRulesDefault   = "<repository root>/allocate.txt"
+	allocateRulesUsage     = "The file that describes how money should be divided between budgets."
+)
+
+var allocateCmd = &cobra.Command{
+	Use:     "allocate {amount} {budget | account}",
+	Aliases: []string{"alloc"},
+	Short:   "Divides money between budgets according to a rules file.",
+	Long: `Splits an amount between several budgets at once. When the source is an account,
+the money is treated as newly arrived, and the account is credited the full
+amount. When the source is a budget, the money is moved out of it.
+
+How much each budget receives is read from a rules file, by default
+"allocate.txt" at the root of the repository. Each line names a budget relative
+to the root, followed by a fixed amount, a percentage of the full amount, or
+"rest":
+
+    budget/rent        USD 500
+    budget/savings     20%
+    budget/groceries   rest
+
+Fixed amounts are set aside first, then percentages, and whatever is left goes
+to the single budget marked "rest".`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		amount, err := envelopes.ParseBalance([]byte(args[0]))
+		if err != nil {
+			logrus.Fatalf("%q not recognized as an amount", args[0])
+		}
+
+		dryrun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		srcPath := args[1]
+		root, err := index.RootDirectory(srcPath)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		unlock := lockRepository(ctx, cmd, root)
+		defer unlock()
+
+		srcIsAccount := false
+		if _, err = index.AccountName(srcPath); err == nil {
+			srcIsAccount = true
+		} else if _, err = index.BudgetName(srcPath); err != nil {
+			logrus.Fatalf("%q was recognized as neither a budget nor an account", srcPath)
+		}
+
+		rulesLoc := filepath.Join(root, "allocate.txt")
+		if cmd.Flags().Changed(allocateRulesFlag) {
+			rulesLoc, err = cmd.Flags().GetString(allocateRulesFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		rulesFile, err := os.Open(rulesLoc)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		rules, err := allocate.ReadRules(ctx, rulesFile)
+		rulesFile.Close()
+		if err != nil {
+			logrus.Fatalf("couldn't read %s: %v", rulesLoc, err)
+		}
+
+		allocations, err := allocate.Split(rules, amount)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		// Everything is loaded up front, and keyed by location, so that nothing is written unless all of the budgets
+		// involved could be found.
+		srcLoc, err := filepath.Abs(srcPath)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		loaded := make(map[string]*envelopes.Budget, len(allocations)+1)
+		order := make([]string, 0, len(allocations)+1)
+		load := func(location string) *envelopes.Budget {
+			if existing, ok := loaded[location]; ok {
+				return existing
+			}
+
+			bdg, err := index.LoadBudget(ctx, location)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			loaded[location] = bdg
+			order = append(order, location)
+			return bdg
+		}
+
+		// Accounts and budgets are stored differently, so an account that is being allocated from is kept apart.
+		var srcAccount envelopes.Balance
+		if srcIsAccount {
+			srcAccount, err = index.LoadAccount(ctx, srcLoc)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			srcAccount = srcAccount.Add(amount)
+		} else {
+			src := load(srcLoc)
+			src.Balance = src.Balance.Sub(amount)
+		}
+
+		for _, allocation := range allocations {
+			if dir, _ := index.SplitEntityName(allocation.Target); dir != index.BudgetDir {
+				logrus.Fatalf("%q in %s does not name a budget", allocation.Target, rulesLoc)
+			}
+
+			target := load(filepath.Join(root, filepath.FromSlash(allocation.Target)))
+			target.Balance = target.Balance.Add(allocation.Amount)
+		}
+
+		err = writeAllocations(cmd.OutOrStdout(), allocations, amount)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if dryrun {
+			return
+		}
+
+		if srcIsAccount {
+			err = index.WriteAccount(ctx, srcLoc, srcAccount)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		for _, location := range order {
+			err = index.WriteBudget(ctx, location, *loaded[location])
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(allocateCmd)
+
+	allocateCmd.Flags().StringP(allocateRulesFlag, allocateRulesShorthand, allocateRulesDefault, allocateRulesUsage)
+	allocateCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, "Print how the amount would be divided, without changing any balances.")
+}
+
+func writeAllocations(output io.Writer, allocations []allocate.Allocation, total envelopes.Balance) (err error) {
+	for _, allocation := range allocations {
+		_, err = fmt.Fprintf(output, "%s\t%s\n", allocation.Target, allocation.Amount)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = fmt.Fprintf(output, "Total: %s\n", total)
+	return
+}
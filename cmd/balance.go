@@ -23,11 +23,13 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/marstr/envelopes"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/marstr/baronial/internal/format"
 	"github.com/marstr/baronial/internal/index"
 )
 
@@ -87,27 +89,62 @@ var balanceCmd = &cobra.Command{
 			logrus.Info(err)
 		}
 
+		outputFormat, err := getOutputFormat(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		store, in, err := getConversion(ctx, cmd, root)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		now := time.Now()
+
+		var document format.BalancesDocument
+
 		if accountsDir != "" {
 			accs, err := index.LoadAccounts(ctx, accountsDir)
-			if err == nil {
+			if err == nil && store != nil {
+				var converted envelopes.State
+				converted, err = store.ConvertState(envelopes.State{Accounts: accs}, in, now)
+				accs = converted.Accounts
+			}
+
+			if err != nil {
+				logrus.Error(err)
+			} else if outputFormat == format.OutputText {
 				err = writeAccountBalances(ctx, os.Stdout, accs)
 				if err != nil {
 					logrus.Fatal(err)
 				}
 			} else {
-				logrus.Error(err)
+				document.Accounts = format.NewAccountsDocument(accs)
 			}
 		}
 
 		if budgetDir != "" {
 			bdg, err := index.LoadBudget(ctx, budgetDir)
-			if err == nil {
+			if err == nil && store != nil {
+				bdg, err = store.ConvertBudget(*bdg, in, now)
+			}
+
+			if err != nil {
+				logrus.Error(err)
+			} else if outputFormat == format.OutputText {
 				err = writeBudgetBalances(ctx, os.Stdout, *bdg)
 				if err != nil {
 					logrus.Fatal(err)
 				}
 			} else {
-				logrus.Error(err)
+				budgetDoc := format.NewBudgetDocument(*bdg)
+				document.Budget = &budgetDoc
+			}
+		}
+
+		if outputFormat != format.OutputText {
+			err = format.WriteDocument(os.Stdout, outputFormat, document)
+			if err != nil {
+				logrus.Fatal(err)
 			}
 		}
 
@@ -118,6 +155,8 @@ var balanceCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(balanceCmd)
 
+	supportStructuredOutput(balanceCmd)
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -134,6 +173,7 @@ func init() {
 		balanceDepthDefault,
 		"How recursively deep the balance tree should be shown before being truncated.",
 	)
+	balanceCmd.Flags().String(inFlag, "", inUsage)
 }
 
 func writeBudgetBalances(_ context.Context, output io.Writer, budget envelopes.Budget) (err error) {
@@ -0,0 +1,144 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+// openCleanIndex prepares to commit transactions that are computed in memory, rather than staged in the index by
+// hand. It finds the repository and the State at HEAD, and makes sure that the index doesn't have any changes that
+// would be lost when it is later overwritten. The activity parameter describes what the caller is about to do, for
+// use in error messages.
+func openCleanIndex(ctx context.Context, activity string) (string, persist.RepositoryReaderWriter, envelopes.ID, envelopes.State, error) {
+	root, err := index.RootDirectory(".")
+	if err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	}
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	var repo persist.RepositoryReaderWriter
+	repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+	if err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	}
+
+	if inProg, err := MergeIsInProgress(ctx, repoLoc); err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	} else if inProg {
+		return "", nil, envelopes.ID{}, envelopes.State{}, fmt.Errorf("a merge is in progress, commit or abort it before %s", activity)
+	}
+
+	if inProg, err := RevertIsInProgress(ctx, repoLoc); err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	} else if inProg {
+		return "", nil, envelopes.ID{}, envelopes.State{}, fmt.Errorf("a revert is in progress, commit or abort it before %s", activity)
+	}
+
+	headID, err := persist.Resolve(ctx, repo, persist.MostRecentTransactionAlias)
+	if err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	}
+
+	current, err := loadStateAt(ctx, repo, headID)
+	if err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	}
+
+	err = requireCleanIndex(ctx, root, current, activity)
+	if err != nil {
+		return "", nil, envelopes.ID{}, envelopes.State{}, err
+	}
+
+	return root, repo, headID, current, nil
+}
+
+// requireCleanIndex fails when the index has changes that haven't been committed on top of head.
+func requireCleanIndex(ctx context.Context, root string, head envelopes.State, activity string) error {
+	indexState, err := index.LoadState(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	if !indexState.Equal(head) {
+		return fmt.Errorf("the index has uncommitted changes, commit or checkout before %s", activity)
+	}
+	return nil
+}
+
+// batchCommit commits transactions that were computed in memory one after another. Once any of them has been
+// committed, HEAD has moved past the index, so Close must be called whether or not the rest of the batch succeeds.
+// Otherwise, every later command would report changes in the index that the user never made.
+type batchCommit struct {
+	root      string
+	repo      persist.RepositoryReaderWriter
+	committed *envelopes.State
+}
+
+// Commit writes a transaction on top of HEAD.
+func (b *batchCommit) Commit(ctx context.Context, transaction envelopes.Transaction) error {
+	err := persist.Commit(ctx, b.repo, transaction)
+	if err != nil {
+		return err
+	}
+	b.committed = transaction.State
+	return nil
+}
+
+// Close checks out the State of the last transaction that was committed, if there was one.
+func (b *batchCommit) Close(ctx context.Context) error {
+	if b.committed == nil {
+		return nil
+	}
+	return index.CheckoutState(ctx, b.committed, b.root, 0660)
+}
+
+// loadStateAt fetches the State associated with a Transaction, treating the empty ID as a repository without any
+// transactions in it.
+func loadStateAt(ctx context.Context, loader persist.Loader, id envelopes.ID) (envelopes.State, error) {
+	if id.Equal(envelopes.ID{}) {
+		return envelopes.State{
+			Accounts: envelopes.Accounts{},
+			Budget:   &envelopes.Budget{},
+		}, nil
+	}
+
+	var transaction envelopes.Transaction
+	err := loader.LoadTransaction(ctx, id, &transaction)
+	if err != nil {
+		return envelopes.State{}, err
+	}
+
+	if transaction.State == nil {
+		return envelopes.State{Accounts: envelopes.Accounts{}, Budget: &envelopes.Budget{}}, nil
+	}
+
+	retval := transaction.State.DeepCopy()
+	if retval.Accounts == nil {
+		retval.Accounts = envelopes.Accounts{}
+	}
+	return retval, nil
+}
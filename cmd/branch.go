@@ -26,11 +26,11 @@ import (
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 var branchCmd = &cobra.Command{
@@ -50,7 +50,7 @@ var branchCmd = &cobra.Command{
 		}
 
 		var repo persist.RepositoryReaderWriter
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, path.Join(indexRootDir, index.RepoName), 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, path.Join(indexRootDir, index.RepoName), 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -62,6 +62,9 @@ var branchCmd = &cobra.Command{
 		}
 
 		if len(args) > 0 {
+			unlock := lockRepository(ctx, cmd, indexRootDir)
+			defer unlock()
+
 			branchName := args[0]
 
 			var target envelopes.ID
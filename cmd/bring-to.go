@@ -31,6 +31,9 @@ func RunBringTo(cmd *cobra.Command, args []string) error {
 	ctx, cancel := RootContext(cmd)
 	defer cancel()
 
+	unlock := lockRepository(ctx, cmd, args[1])
+	defer unlock()
+
 	desiredBal, err := envelopes.ParseBalance([]byte(args[0]))
 	if err != nil {
 		return err
@@ -20,11 +20,11 @@ import (
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 var checkoutCmd = &cobra.Command{
@@ -42,12 +42,15 @@ var checkoutCmd = &cobra.Command{
 		if err != nil {
 			logrus.Fatal(err)
 		}
+
+		unlock := lockRepository(ctx, cmd, root)
+		defer unlock()
 		root = path.Join(root, index.RepoName)
 
 		requested := persist.RefSpec(args[0])
 
 		var repo persist.RepositoryReaderWriter
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, root, 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, root, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
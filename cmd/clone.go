@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone {source} [dir]",
+	Short: "Creates a new Baronial repository with a copy of every transaction and branch in another one.",
+	Long: `Creates a new Baronial repository with a copy of every transaction and branch
+in another one, then checks out whatever the source has checked out. Changes in
+the source's index that haven't been committed are not copied.
+
+The source is recorded as the remote "origin", so that "fetch", "push", and
+"pull" can be used to keep the two in sync. When no directory is named, one is
+created in the current working directory with the same name as the source.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		source, err := filepath.Abs(args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var dir string
+		if len(args) > 1 {
+			dir = args[1]
+		} else {
+			dir = filepath.Base(strings.TrimSuffix(source, string(filepath.Separator)+index.RepoName))
+		}
+
+		entries, err := os.ReadDir(dir)
+		created := os.IsNotExist(err)
+		if err == nil && len(entries) > 0 {
+			logrus.Fatalf("%q already exists and isn't empty", dir)
+		} else if err != nil && !created {
+			logrus.Fatal(err)
+		}
+
+		err = cloneRepository(ctx, source, dir)
+		if err != nil {
+			// Leave nothing half-copied behind for someone to mistake for a working repository.
+			cleanup := []string{index.RepoName, index.AccountsDir, index.BudgetDir}
+			if created {
+				cleanup = []string{"."}
+			}
+			for _, entry := range cleanup {
+				if cleanupErr := os.RemoveAll(filepath.Join(dir, entry)); cleanupErr != nil {
+					logrus.Warn("couldn't clean up partially cloned repository: ", cleanupErr)
+				}
+			}
+			logrus.Fatal(err)
+		}
+	},
+}
+
+// cloneRepository creates a new index in dir, copies every transaction and branch from source into it, then checks out
+// whatever source has checked out.
+func cloneRepository(ctx context.Context, source, dir string) error {
+	src, err := remote.Open(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	current, err := src.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't read what the source has checked out: %w", err)
+	}
+
+	err = os.MkdirAll(dir, 0750)
+	if err != nil {
+		return err
+	}
+
+	err = createIndexDirs(dir)
+	if err != nil {
+		return err
+	}
+
+	repoLoc := filepath.Join(dir, index.RepoName)
+	repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+	if err != nil {
+		return err
+	}
+
+	origin := remote.Remote{Name: remote.DefaultName, Location: source}
+	err = remote.Add(ctx, repoLoc, origin.Name, origin.Location)
+	if err != nil {
+		return err
+	}
+
+	_, err = remote.Fetch(ctx, repo, repoLoc, origin)
+	if err != nil {
+		return err
+	}
+
+	branches, err := remote.ListTracking(ctx, repoLoc, origin.Name)
+	if err != nil {
+		return err
+	}
+
+	for branch, head := range branches {
+		err = repo.WriteBranch(ctx, branch, head)
+		if err != nil {
+			return err
+		}
+	}
+
+	if current == "" {
+		current = persist.DefaultBranch
+		err = repo.WriteBranch(ctx, string(current), envelopes.ID{})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = repo.SetCurrent(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	head, err := persist.Resolve(ctx, repo, current)
+	if err != nil {
+		return err
+	}
+
+	if head.Equal(envelopes.ID{}) {
+		return nil
+	}
+
+	var target envelopes.Transaction
+	err = repo.LoadTransaction(ctx, head, &target)
+	if err != nil {
+		return err
+	}
+
+	return index.CheckoutTransaction(ctx, &target, dir, 0660)
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+}
@@ -26,13 +26,13 @@ import (
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/format"
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 const (
@@ -143,6 +143,9 @@ var commitCmd = &cobra.Command{
 			logrus.Fatal(err)
 		}
 
+		unlock := lockRepository(ctx, cmd, targetDir)
+		defer unlock()
+
 		if !cmd.Flags().Changed(amountFlag) {
 			var err error
 			commitTransactionFromFlags.Amount, err = calculateAmount(ctx, ".")
@@ -156,11 +159,7 @@ var commitCmd = &cobra.Command{
 			logrus.Fatal(err)
 		}
 
-		budgetBal := commitTransactionFromFlags.State.Budget.RecursiveBalance()
-		var accountsBal envelopes.Balance
-		for _, entry := range commitTransactionFromFlags.State.Accounts {
-			accountsBal = accountsBal.Add(entry)
-		}
+		accountsBal, budgetBal := totalBalances(*commitTransactionFromFlags.State)
 
 		var force bool
 		force, err = cmd.Flags().GetBool(forceFlag)
@@ -193,7 +192,7 @@ var commitCmd = &cobra.Command{
 
 		repoLoc := filepath.Join(targetDir, index.RepoName)
 		var repo persist.RepositoryReaderWriter
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -223,6 +222,14 @@ var commitCmd = &cobra.Command{
 				logrus.Fatal("unable to read pending merge")
 			}
 
+			if len(mergeParams.Conflicts) > 0 {
+				paths := make([]string, len(mergeParams.Conflicts))
+				for i, conflict := range mergeParams.Conflicts {
+					paths[i] = conflict.Path
+				}
+				logrus.Fatalf("the merge has unresolved conflicts in %s, see \"merge --status\"", strings.Join(paths, ", "))
+			}
+
 			additionalParents = mergeParams.Parents[1:]
 
 			if commitTransactionFromFlags.Comment == "" {
@@ -397,7 +404,7 @@ func calculateAmount(ctx context.Context, targetDir string) (envelopes.Balance,
 	}
 
 	var repo persist.RepositoryReader
-	repo, err = filesystem.OpenRepositoryWithCache(ctx, filepath.Join(targetDir, index.RepoName), 10000)
+	repo, err = pack.OpenRepositoryWithCache(ctx, filepath.Join(targetDir, index.RepoName), 10000)
 	if err != nil {
 		return envelopes.Balance{}, err
 	}
@@ -18,6 +18,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/marstr/envelopes"
 	"github.com/sirupsen/logrus"
@@ -29,26 +30,23 @@ import (
 var creditCmd = &cobra.Command{
 	Use:     "credit {amount} {budget | account} [{budget | account}...]",
 	Aliases: []string{"c", "cr"},
-	Short:   "Makes funds available for one or more category of spending.",
+	Short:   "Makes funds available for one or more category of spending or account.",
 	Args:    creditDebitArgValidation,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := RootContext(cmd)
 		defer cancel()
 
+		unlock := lockRepository(ctx, cmd, args[1])
+		defer unlock()
+
 		rawMagnitude := args[0]
 		magnitude, err := envelopes.ParseBalance([]byte(rawMagnitude))
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		for _, rawBudget := range args[1:] {
-			bdg, err := index.LoadBudget(ctx, rawBudget)
-			if err != nil {
-				logrus.Fatal(err)
-			}
-
-			bdg.Balance = bdg.Balance.Add(magnitude)
-			err = index.WriteBudget(ctx, rawBudget, *bdg)
+		for _, target := range args[1:] {
+			err = adjustBalance(ctx, target, magnitude)
 			if err != nil {
 				logrus.Fatal(err)
 			}
@@ -76,18 +74,62 @@ func creditDebitArgValidation(cmd *cobra.Command, args []string) error {
 	}
 
 	for _, arg := range args[1:] {
-		_, err := index.LoadBudget(ctx, arg)
-		if err == nil {
-			continue
+		if _, err := isAccount(arg); err != nil {
+			return fmt.Errorf("%q was recognized as neither a budget nor an account", arg)
 		}
+	}
+
+	return nil
+}
+
+// isAccount determines whether a directory in the index holds an account or a budget. An error is returned if it is
+// neither.
+func isAccount(dirname string) (bool, error) {
+	info, err := os.Stat(dirname)
+	if err != nil {
+		return false, err
+	}
+
+	if !info.IsDir() {
+		return false, fmt.Errorf("%q is not a directory", dirname)
+	}
 
-		_, err = index.LoadAccounts(ctx, arg)
-		if err == nil {
-			continue
+	name, err := index.AccountName(dirname)
+	if err == nil {
+		if name == "" {
+			return false, index.ErrNotAccount(dirname)
 		}
+		return true, nil
+	} else if _, ok := err.(index.ErrNotAccount); !ok {
+		return false, err
+	}
 
-		return fmt.Errorf("%q was recognized as neither a budget nor an account", arg)
+	_, err = index.BudgetName(dirname)
+	if err != nil {
+		return false, err
 	}
+	return false, nil
+}
 
-	return nil
+// adjustBalance adds delta to the balance of the account or budget in a directory of the index.
+func adjustBalance(ctx context.Context, dirname string, delta envelopes.Balance) error {
+	account, err := isAccount(dirname)
+	if err != nil {
+		return err
+	}
+
+	if account {
+		bal, err := index.LoadAccount(ctx, dirname)
+		if err != nil {
+			return err
+		}
+		return index.WriteAccount(ctx, dirname, bal.Add(delta))
+	}
+
+	bdg, err := index.LoadBudget(ctx, dirname)
+	if err != nil {
+		return err
+	}
+	bdg.Balance = bdg.Balance.Add(delta)
+	return index.WriteBudget(ctx, dirname, *bdg)
 }
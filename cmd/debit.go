@@ -19,19 +19,20 @@ import (
 	"github.com/marstr/envelopes"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-
-	"github.com/marstr/baronial/internal/index"
 )
 
 var debitCmd = &cobra.Command{
 	Use:     `debit {amount} {budget | account} [{budget | account}...]`,
 	Aliases: []string{"d", "dr"},
-	Short:   `Removes funds from a category of spending.`,
+	Short:   `Removes funds from one or more category of spending or account.`,
 	Args:    creditDebitArgValidation,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := RootContext(cmd)
 		defer cancel()
 
+		unlock := lockRepository(ctx, cmd, args[1])
+		defer unlock()
+
 		rawMagnitude := args[0]
 		magnitude, err := envelopes.ParseBalance([]byte(rawMagnitude))
 		if err != nil {
@@ -39,13 +40,7 @@ var debitCmd = &cobra.Command{
 		}
 
 		for _, targetDir := range args[1:] {
-			bdg, err := index.LoadBudget(ctx, targetDir)
-			if err != nil {
-				logrus.Fatal(err)
-			}
-
-			bdg.Balance = bdg.Balance.Sub(magnitude)
-			err = index.WriteBudget(ctx, targetDir, *bdg)
+			err = adjustBalance(ctx, targetDir, magnitude.Negate())
 			if err != nil {
 				logrus.Fatal(err)
 			}
@@ -21,15 +21,18 @@ import (
 	"context"
 	"errors"
 	"path"
+	"time"
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/format"
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/rates"
+	"github.com/marstr/baronial/internal/report"
 )
 
 var diffCmd = &cobra.Command{
@@ -48,82 +51,139 @@ var diffCmd = &cobra.Command{
 		}
 
 		var left, right *envelopes.State
-		left, right, err = getDiffStates(ctx, args, repoRoot)
+		var leftTime, rightTime time.Time
+		left, right, leftTime, rightTime, err = getDiffStates(ctx, args, repoRoot)
 		if err != nil {
 			return
 		}
 
+		store, in, err := getConversion(ctx, cmd, repoRoot)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+
+		if store != nil {
+			var convertedLeft, convertedRight envelopes.State
+			convertedLeft, convertedRight, err = convertDiffStates(store, *left, *right, in, leftTime, rightTime)
+			if err != nil {
+				logrus.Error(err)
+				return
+			}
+			left, right = &convertedLeft, &convertedRight
+		}
+
 		diff := left.Subtract(*right)
 
-		err = format.PrettyPrintImpact(cmd.OutOrStdout(), diff)
+		var outputFormat format.OutputFormat
+		outputFormat, err = getOutputFormat(cmd)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+
+		if outputFormat == format.OutputText {
+			err = format.PrettyPrintImpact(cmd.OutOrStdout(), diff)
+		} else {
+			err = format.WriteDocument(cmd.OutOrStdout(), outputFormat, format.NewImpactDocument(diff))
+		}
 		if err != nil {
 			return
 		}
 	},
 }
 
-func getDiffStates(ctx context.Context, args []string, indexRoot string) (*envelopes.State, *envelopes.State, error) {
+// getDiffStates finds the two states that should be compared, along with the times that each one reflects. The index
+// is considered to reflect the current time, and transactions the time they were posted.
+func getDiffStates(ctx context.Context, args []string, indexRoot string) (*envelopes.State, *envelopes.State, time.Time, time.Time, error) {
 	var err error
 	var left, right *envelopes.State
+	var leftTime, rightTime time.Time
 	var repo persist.RepositoryReader
 
-	repo, err = filesystem.OpenRepositoryWithCache(ctx, path.Join(indexRoot, index.RepoName), 10000)
+	repo, err = pack.OpenRepositoryWithCache(ctx, path.Join(indexRoot, index.RepoName), 10000)
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
-	loadFromRepository := func(ctx context.Context, rs persist.RefSpec) (*envelopes.State, error) {
+	loadFromRepository := func(ctx context.Context, rs persist.RefSpec) (*envelopes.State, time.Time, error) {
 		var targetID envelopes.ID
 		targetID, err = persist.Resolve(ctx, repo, rs)
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 		var target envelopes.Transaction
 		err = repo.LoadTransaction(ctx, targetID, &target)
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
-		return target.State, nil
+		return target.State, report.EffectiveTime(target), nil
 	}
 
 	switch len(args) {
 	case 0: // Compare current index against HEAD
-		right, err = loadFromRepository(ctx, persist.MostRecentTransactionAlias)
+		right, rightTime, err = loadFromRepository(ctx, persist.MostRecentTransactionAlias)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
 
 		left, err = index.LoadState(ctx, indexRoot)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
+		leftTime = time.Now()
 	case 1: // Compare current index against specified refspec
-		right, err = loadFromRepository(ctx, persist.RefSpec(args[0]))
+		right, rightTime, err = loadFromRepository(ctx, persist.RefSpec(args[0]))
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
 
 		left, err = index.LoadState(ctx, indexRoot)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
+		leftTime = time.Now()
 	case 2: // Compare the two arbitrary refspecs
-		right, err = loadFromRepository(ctx, persist.RefSpec(args[0]))
+		right, rightTime, err = loadFromRepository(ctx, persist.RefSpec(args[0]))
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
 
-		left, err = loadFromRepository(ctx, persist.RefSpec(args[1]))
+		left, leftTime, err = loadFromRepository(ctx, persist.RefSpec(args[1]))
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, time.Time{}, time.Time{}, err
 		}
 	default:
-		return nil, nil, errors.New("too many arguments")
+		return nil, nil, time.Time{}, time.Time{}, errors.New("too many arguments")
 	}
 
-	return left, right, nil
+	return left, right, leftTime, rightTime, nil
+}
+
+// convertDiffStates values both sides of a diff in terms of a single asset. Both sides are converted using the rates in
+// effect at the later of their two times, so that a change in rates alone doesn't show up as a difference.
+func convertDiffStates(store *rates.Store, left, right envelopes.State, in envelopes.AssetType, leftTime, rightTime time.Time) (envelopes.State, envelopes.State, error) {
+	when := leftTime
+	if rightTime.After(when) {
+		when = rightTime
+	}
+
+	convertedLeft, err := store.ConvertState(left, in, when)
+	if err != nil {
+		return envelopes.State{}, envelopes.State{}, err
+	}
+
+	convertedRight, err := store.ConvertState(right, in, when)
+	if err != nil {
+		return envelopes.State{}, envelopes.State{}, err
+	}
+
+	return convertedLeft, convertedRight, nil
 }
 
 func init() {
 	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().String(inFlag, "", inUsage)
+	supportStructuredOutput(diffCmd)
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+
+	"github.com/marstr/baronial/internal/rates"
+)
+
+func TestConvertDiffStates_rateMovesOnly(t *testing.T) {
+	january := time.Date(2026, time.January, 15, 12, 0, 0, 0, time.UTC)
+	february := time.Date(2026, time.February, 15, 12, 0, 0, 0, time.UTC)
+
+	store := rates.NewStore()
+	store.Add(rates.Rate{Date: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), Asset: "EUR", In: "USD", Value: big.NewRat(110, 100)})
+	store.Add(rates.Rate{Date: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), Asset: "EUR", In: "USD", Value: big.NewRat(120, 100)})
+
+	holdings := func() envelopes.State {
+		return envelopes.State{
+			Accounts: envelopes.Accounts{"savings": envelopes.Balance{"EUR": big.NewRat(100, 1)}},
+			Budget:   &envelopes.Budget{Balance: envelopes.Balance{"EUR": big.NewRat(100, 1)}},
+		}
+	}
+
+	for _, times := range [][2]time.Time{{february, january}, {january, february}} {
+		left, right, err := convertDiffStates(store, holdings(), holdings(), "USD", times[0], times[1])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !left.Equal(right) {
+			t.Logf("converting unchanged holdings at %s and %s produced a difference:\n\tleft:  %v\n\tright: %v", times[0].Format("2006-01-02"), times[1].Format("2006-01-02"), left.Accounts, right.Accounts)
+			t.Fail()
+		}
+
+		expected := envelopes.Balance{"USD": big.NewRat(120, 1)}
+		if got := left.Accounts["savings"]; !got.Equal(expected) {
+			t.Logf("got %s, want %s (the rate as of the later date)", got, expected)
+			t.Fail()
+		}
+	}
+}
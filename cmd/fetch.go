@@ -0,0 +1,74 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch [remote]",
+	Short: "Copies transactions from a remote, without changing any local branches.",
+	Long: `Copies every transaction on a remote's branches that this repository doesn't
+have yet, and records where each of those branches are pointing. Local branches
+and the index are left alone; use "merge" or "pull" to bring the changes in.
+
+When no remote is named, "origin" is used, or the only remote if there is just
+one.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		unlock := lockRepository(ctx, cmd, repoLoc)
+		defer unlock()
+
+		from, err := getRemote(ctx, repoLoc, args)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		updates, err := remote.Fetch(ctx, repo, repoLoc, from)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		for _, update := range updates {
+			fmt.Println(update)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+}
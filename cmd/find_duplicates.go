@@ -0,0 +1,230 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/duplicate"
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+const (
+	findDuplicatesToleranceFlag    = "tolerance"
+	findDuplicatesToleranceDefault = 48 * time.Hour
+	findDuplicatesToleranceUsage   = "How far apart transactions with the same amount and merchant may be and still be considered duplicates."
+)
+
+var findDuplicatesCmd = &cobra.Command{
+	Use:   "find-duplicates",
+	Short: "Finds transactions that seem to have been recorded more than once.",
+	Long: `Searches the history of the current branch for transactions that share a bank
+record ID, or that have the same amount and merchant and happened within
+"--tolerance" of each other. Merges, reverts, and transactions that have already
+been reverted are skipped.
+
+For each set of duplicates, the one entered first is presumed to be the original.
+You'll be offered the chance to revert the rest, just as "revert" would. Check
+the balances afterwards, then commit to finish.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		tolerance, err := cmd.Flags().GetDuration(findDuplicatesToleranceFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if tolerance < 0 {
+			logrus.Fatalf("--%s must not be negative", findDuplicatesToleranceFlag)
+		}
+
+		dryRun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		// Transactions that a revert in progress already undoes mustn't be undone a second time.
+		pending := make(map[envelopes.ID]struct{})
+		if !dryRun {
+			unlock := lockRepository(ctx, cmd, root)
+			defer unlock()
+
+			var inProg bool
+			inProg, err = MergeIsInProgress(ctx, repoLoc)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if inProg {
+				logrus.Warn("a merge is in progress, so duplicates won't be offered for reverting")
+				dryRun = true
+			}
+
+			inProg, err = RevertIsInProgress(ctx, repoLoc)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if inProg {
+				var params RevertParameters
+				err = RevertUnstowProgress(ctx, repoLoc, &params)
+				if err != nil {
+					logrus.Fatal(err)
+				}
+				for _, id := range params.Reverts {
+					pending[id] = struct{}{}
+				}
+			}
+		}
+
+		var repo persist.RepositoryReader
+		repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		currentRef, err := repo.Current(ctx)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		currentID, err := persist.Resolve(ctx, repo, currentRef)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		groups, err := duplicate.Find(ctx, repo, tolerance, currentID)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if len(groups) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No duplicates found.")
+			return
+		}
+
+		input := bufio.NewReader(cmd.InOrStdin())
+		reverted := 0
+		for i, group := range groups {
+			if i > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+
+			err = printDuplicateGroup(ctx, cmd.OutOrStdout(), repo, group)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			if dryRun {
+				continue
+			}
+
+			var toRevert []envelopes.ID
+			for _, extra := range group.Extras() {
+				if _, ok := pending[extra.ID]; !ok {
+					toRevert = append(toRevert, extra.ID)
+				}
+			}
+			if len(toRevert) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "The transaction(s) entered after the first are already being reverted.")
+				continue
+			}
+
+			var revert bool
+			revert, err = promptToContinue(ctx, fmt.Sprintf("Revert the %d transaction(s) entered after the first?", len(toRevert)), cmd.OutOrStdout(), input)
+			if errors.Is(err, io.EOF) {
+				// Running out of input is the same as not answering, which means no.
+				fmt.Fprintln(cmd.OutOrStdout())
+				revert = false
+			} else if err != nil {
+				logrus.Fatal(err)
+			}
+			if !revert {
+				continue
+			}
+
+			for _, id := range toRevert {
+				err = revertTransaction(ctx, root, repo, id)
+				if err != nil {
+					logrus.Fatal(err)
+				}
+				pending[id] = struct{}{}
+				reverted++
+			}
+		}
+
+		if reverted > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nUndid the effects of %d transaction(s). Please check current balances for accuracy, make any necessary edits, then commit.\n", reverted)
+		}
+	},
+}
+
+// printDuplicateGroup writes why a group of transactions seem to be duplicates, followed by a line for each of them.
+func printDuplicateGroup(ctx context.Context, output io.Writer, loader persist.Loader, group duplicate.Group) error {
+	oneline, err := format.ParseTransactionTemplate(format.PresetOneline)
+	if err != nil {
+		return err
+	}
+
+	if group.Reason == duplicate.ReasonRecordID {
+		_, err = fmt.Fprintf(output, "Transactions with the %s %q:\n", group.Reason, group.Entries[0].Transaction.RecordID)
+	} else {
+		_, err = fmt.Fprintf(output, "Transactions with the %s:\n", group.Reason)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range group.Entries {
+		_, err = fmt.Fprint(output, "\t")
+		if err != nil {
+			return err
+		}
+
+		err = oneline.Execute(ctx, output, loader, entry.Transaction)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(findDuplicatesCmd)
+
+	findDuplicatesCmd.Flags().Duration(findDuplicatesToleranceFlag, findDuplicatesToleranceDefault, findDuplicatesToleranceUsage)
+	findDuplicatesCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, "List the duplicates without offering to revert them.")
+}
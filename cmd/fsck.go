@@ -0,0 +1,208 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/marstr/envelopes/persist/filesystem"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/fsck"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Checks the history of the repository for missing or damaged transactions.",
+	Long: `Walks the history of every branch, remote branch, and any merge or revert in
+progress, looking for:
+  - Transactions, parents, or reverted transactions that can't be loaded.
+  - Transactions whose contents don't match the ID they're stored under.
+  - Transactions whose amount doesn't match the change from their parent.
+  - Transactions whose accounts and budget don't balance.
+
+Afterwards, transactions that aren't in the history of any branch, and other
+objects that don't belong to any transaction, are listed.
+
+Missing and damaged transactions are errors, and cause the command to fail.
+Everything else is only reported.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		heads, err := getRepositoryHeads(ctx, repoLoc, repo, false)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		report, err := fsck.Check(ctx, repo, heads)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = printFsckReport(os.Stdout, report, len(heads))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if count := report.Errors(); count > 0 {
+			logrus.Fatalf("found %d error(s) in the repository", count)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+}
+
+// getRepositoryHeads finds every reference into the history of a repository: local and remote branches, a detached
+// checkout, and the transactions that a merge or revert in progress refers to.
+//
+// When strict is false, a checkout that can't be resolved is only warned about, so that the rest of the repository can
+// still be looked at. Anything that deletes what isn't reachable needs strict, or it could delete the checkout's history.
+func getRepositoryHeads(ctx context.Context, repoLoc string, repo *filesystem.Repository, strict bool) (map[string]envelopes.ID, error) {
+	retval := make(map[string]envelopes.ID)
+
+	branches, err := repo.ListBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for branch := range branches {
+		retval[branch], err = repo.ReadBranch(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	remotes, err := remote.Load(ctx, repoLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range remotes {
+		var tracked map[string]envelopes.ID
+		tracked, err = remote.ListTracking(ctx, repoLoc, r.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for branch, head := range tracked {
+			retval[r.Name+"/"+branch] = head
+		}
+	}
+
+	current, err := repo.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := retval[string(current)]; !ok && current != "" {
+		var head envelopes.ID
+		head, err = persist.Resolve(ctx, repo, current)
+		if err != nil && strict {
+			return nil, fmt.Errorf("couldn't resolve the checked out %q: %w", current, err)
+		} else if err != nil {
+			logrus.Warnf("couldn't resolve the checked out %q, its history won't be checked: %v", current, err)
+		} else {
+			retval[persist.MostRecentTransactionAlias] = head
+		}
+	}
+
+	if inProg, err := MergeIsInProgress(ctx, repoLoc); err != nil {
+		return nil, err
+	} else if inProg {
+		var params MergeParameters
+		err = MergeUnstowProgress(ctx, repoLoc, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, parent := range params.Parents {
+			retval[fmt.Sprintf("the merge in progress (parent %d)", i+1)] = parent
+		}
+	}
+
+	if inProg, err := RevertIsInProgress(ctx, repoLoc); err != nil {
+		return nil, err
+	} else if inProg {
+		var params RevertParameters
+		err = RevertUnstowProgress(ctx, repoLoc, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, reverted := range params.Reverts {
+			retval[fmt.Sprintf("the revert in progress (transaction %d)", i+1)] = reverted
+		}
+	}
+
+	return retval, nil
+}
+
+func printFsckReport(output io.Writer, report fsck.Report, heads int) error {
+	_, err := fmt.Fprintf(output, "Checked %d transaction(s) reachable from %d head(s).\n", report.Transactions, heads)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range report.Problems {
+		_, err = fmt.Fprintln(output, problem)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range report.Unreachable {
+		_, err = fmt.Fprintf(output, "unreachable transaction %s\n", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range report.Dangling {
+		_, err = fmt.Fprintf(output, "dangling object %s\n", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(report.Problems) == 0 && len(report.Unreachable) == 0 && len(report.Dangling) == 0 {
+		_, err = fmt.Fprintln(output, "No problems were found.")
+	}
+	return err
+}
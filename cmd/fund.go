@@ -0,0 +1,259 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+)
+
+const (
+	fundIncomeFlag      = "income"
+	fundIncomeShorthand = "i"
+	fundIncomeUsage     = "The amount of income being distributed, used by goals that ask for a percentage."
+)
+
+type fundStep struct {
+	Name    string
+	Goal    index.Goal
+	Balance envelopes.Balance
+	Needed  envelopes.Balance
+}
+
+var fundCmd = &cobra.Command{
+	Use:   "fund {src}",
+	Short: "Transfers funds from one budget to every budget with a goal, as much as each goal needs.",
+	Long: `Looks for budgets with a "goal.txt" file next to their "cash.txt", works out how
+much each of them needs, and moves that amount out of the source budget, the
+same way "bring-to" does. A goal file contains a single line in one of these
+forms:
+
+    monthly USD 200                  Refill the budget up to USD 200.
+    target USD 1200 by 2026-12-01    Save towards USD 1200, spread evenly over
+                                     the months remaining before the deadline.
+    percent 10                       Set aside 10% of the amount passed to
+                                     --income.
+
+Goals never take money out of a budget, and amounts are rounded up to the
+nearest cent. Nested budgets are funded before their parents, and a parent's
+goal considers the balance of its children. The plan is always printed; use
+--dry-run to see it without changing any balances.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		dryrun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var income envelopes.Balance
+		if cmd.Flags().Changed(fundIncomeFlag) {
+			var rawIncome string
+			rawIncome, err = cmd.Flags().GetString(fundIncomeFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			income, err = envelopes.ParseBalance([]byte(rawIncome))
+			if err != nil {
+				logrus.Fatalf("%q not recognized as an amount", rawIncome)
+			}
+		}
+
+		srcPath := args[0]
+		srcName, err := index.BudgetName(srcPath)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		root, err := index.RootDirectory(srcPath)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		unlock := lockRepository(ctx, cmd, root)
+		defer unlock()
+		budgetDir := filepath.Join(root, index.BudgetDir)
+
+		budget, err := index.LoadBudget(ctx, budgetDir)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		src := findBudget(budget, srcName)
+		if src == nil {
+			logrus.Fatalf("budget %q does not exist", srcPath)
+		}
+
+		goals, err := index.LoadGoals(ctx, budgetDir)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		plan, total := planFunding(budget, srcName, goals, time.Now(), income)
+
+		err = writeFundPlan(cmd.OutOrStdout(), srcName, plan, total)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		for asset, magnitude := range src.RecursiveBalance() {
+			if magnitude.Sign() < 0 {
+				logrus.Warnf("funding these goals leaves %q with a negative %s balance", srcPath, asset)
+			}
+		}
+
+		if dryrun {
+			return
+		}
+
+		for _, step := range plan {
+			if step.Needed.Equal(envelopes.Balance{}) {
+				continue
+			}
+
+			err = index.WriteBudget(ctx, filepath.Join(budgetDir, filepath.FromSlash(step.Name)), *findBudget(budget, step.Name))
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		err = index.WriteBudget(ctx, filepath.Join(budgetDir, filepath.FromSlash(srcName)), *src)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fundCmd)
+
+	fundCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, "Print how much each budget would receive, without transferring anything.")
+	fundCmd.Flags().StringP(fundIncomeFlag, fundIncomeShorthand, "", fundIncomeUsage)
+}
+
+// planFunding works out how much each budget with a goal needs, and moves that much out of the source budget. Nested
+// budgets are funded before their parents, so that a parent's goal considers what its children were given. The
+// balances in budget are updated to reflect the plan.
+func planFunding(budget *envelopes.Budget, srcName string, goals map[string]index.Goal, now time.Time, income envelopes.Balance) ([]fundStep, envelopes.Balance) {
+	src := findBudget(budget, srcName)
+
+	names := make([]string, 0, len(goals))
+	for name := range goals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		iDepth, jDepth := budgetDepth(names[i]), budgetDepth(names[j])
+		if iDepth != jDepth {
+			return iDepth > jDepth
+		}
+		return names[i] < names[j]
+	})
+
+	var plan []fundStep
+	var total envelopes.Balance
+	for _, name := range names {
+		if name == srcName || strings.HasPrefix(srcName+"/", name+"/") || name == "" {
+			logrus.Warnf("skipping the goal for %q because it contains the source budget", name)
+			continue
+		}
+
+		target := findBudget(budget, name)
+		if target == nil {
+			continue
+		}
+
+		current := target.RecursiveBalance()
+		needed := goals[name].Needed(current, now, income)
+
+		target.Balance = target.Balance.Add(needed)
+		src.Balance = src.Balance.Sub(needed)
+		total = total.Add(needed)
+
+		plan = append(plan, fundStep{Name: name, Goal: goals[name], Balance: current, Needed: needed})
+	}
+
+	return plan, total
+}
+
+// findBudget locates a budget by its slash separated name, relative to the root of a tree of budgets. If no such
+// budget exists, nil is returned.
+func findBudget(root *envelopes.Budget, name string) *envelopes.Budget {
+	current := root
+	if name == "" {
+		return current
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		child, ok := current.Children[segment]
+		if !ok {
+			return nil
+		}
+		current = child
+	}
+	return current
+}
+
+func budgetDepth(name string) int {
+	if name == "" {
+		return 0
+	}
+	return strings.Count(name, "/") + 1
+}
+
+// budgetEntityName qualifies the name of a budget the same way entities are named on the command line, for example
+// "budget/groceries".
+func budgetEntityName(name string) string {
+	if name == "" {
+		return index.BudgetDir
+	}
+	return index.BudgetDir + "/" + name
+}
+
+func writeFundPlan(output io.Writer, srcName string, plan []fundStep, total envelopes.Balance) (err error) {
+	if len(plan) == 0 {
+		_, err = fmt.Fprintln(output, "No budgets have goals to fund.")
+		return
+	}
+
+	for _, step := range plan {
+		needed := "(funded)"
+		if !step.Needed.Equal(envelopes.Balance{}) {
+			needed = step.Needed.String()
+		}
+
+		_, err = fmt.Fprintf(output, "%s\t%s\t%s\t%s\n", budgetEntityName(step.Name), step.Goal, step.Balance, needed)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = fmt.Fprintf(output, "Total: %s from %s\n", total, budgetEntityName(srcName))
+	return
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+
+	"github.com/marstr/baronial/internal/index"
+)
+
+func TestPlanFunding(t *testing.T) {
+	usd := func(amount int64) envelopes.Balance {
+		return envelopes.Balance{"USD": big.NewRat(amount, 1)}
+	}
+
+	parseGoal := func(raw string) index.Goal {
+		goal, err := index.ParseGoal(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return goal
+	}
+
+	budget := &envelopes.Budget{
+		Children: map[string]*envelopes.Budget{
+			"income": {Balance: usd(1000)},
+			"rent":   {Balance: usd(150)},
+			"savings": {
+				Balance: usd(20),
+				Children: map[string]*envelopes.Budget{
+					"car": {Balance: usd(0)},
+				},
+			},
+			"fun": {Balance: usd(300)},
+		},
+	}
+
+	goals := map[string]index.Goal{
+		"":            parseGoal("monthly USD 5000"),
+		"rent":        parseGoal("monthly USD 500"),
+		"savings":     parseGoal("monthly USD 200"),
+		"savings/car": parseGoal("percent 10"),
+		"fun":         parseGoal("monthly USD 100"),
+		"missing":     parseGoal("monthly USD 100"),
+	}
+
+	plan, total := planFunding(budget, "income", goals, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), usd(800))
+
+	expected := []struct {
+		name   string
+		needed envelopes.Balance
+	}{
+		{"savings/car", usd(80)},
+		{"fun", envelopes.Balance{}},
+		{"rent", usd(350)},
+		{"savings", usd(100)},
+	}
+
+	if len(plan) != len(expected) {
+		t.Logf("got %d steps, want %d: %v", len(plan), len(expected), plan)
+		t.FailNow()
+	}
+
+	for i := range expected {
+		if plan[i].Name != expected[i].name || !plan[i].Needed.Equal(expected[i].needed) {
+			t.Logf("step %d\n\tgot:  %s %s\n\twant: %s %s", i, plan[i].Name, plan[i].Needed, expected[i].name, expected[i].needed)
+			t.Fail()
+		}
+	}
+
+	if want := usd(530); !total.Equal(want) {
+		t.Logf("total\n\tgot:  %s\n\twant: %s", total, want)
+		t.Fail()
+	}
+
+	if want := usd(470); !budget.Children["income"].Balance.Equal(want) {
+		t.Logf("source balance\n\tgot:  %s\n\twant: %s", budget.Children["income"].Balance, want)
+		t.Fail()
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/fsck"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+const (
+	gcPruneFlag    = "prune"
+	gcPruneDefault = 14 * 24 * time.Hour
+	gcPruneUsage   = "How long a transaction must have been unreachable before it is deleted."
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Deletes unreachable transactions and packs the rest into a single file.",
+	Long: `Finds the transactions that aren't in the history of any branch, remote branch,
+or merge or revert in progress. Those that have been unreachable for longer than
+"--prune" are deleted, along with everything else stored with them.
+
+Everything that is still reachable is packed into a single file, which is much
+faster to read than one file per object. New transactions are still written to
+files of their own until the next time "gc" is run.
+
+If any transaction in the history can't be loaded, nothing is deleted. Use
+"fsck" to find out what's wrong.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		grace, err := cmd.Flags().GetDuration(gcPruneFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		dryRun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		unlock := lockRepository(ctx, cmd, repoLoc)
+		defer unlock()
+
+		config, err := filesystem.LoadConfig(ctx, repoLoc)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		// Older formats don't store each budget and set of accounts under its own ID, so what's reachable can't be
+		// worked out precisely enough to safely delete anything.
+		if config.Objects.Format != filesystem.FormatJson || config.Objects.Version != 3 {
+			logrus.Fatalf("gc only supports repositories that store their objects in version 3 of the JSON format")
+		}
+
+		repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		heads, err := getRepositoryHeads(ctx, repoLoc, repo, true)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		starts := make([]envelopes.ID, 0, len(heads))
+		for _, head := range heads {
+			starts = append(starts, head)
+		}
+
+		reachable, missing, err := fsck.Reachable(ctx, repo, starts...)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if len(missing) > 0 {
+			logrus.Fatalf("%d transaction(s) in the history can't be loaded, so nothing was deleted. Run \"fsck\" for details", len(missing))
+		}
+
+		summary, err := pack.Collect(ctx, repo, reachable, time.Now().Add(-grace), dryRun)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if dryRun {
+			fmt.Printf("Would delete %d unreachable object(s), pack %d object(s), and keep %d recently unreachable object(s).\n", len(summary.Pruned), summary.Packed, len(summary.Kept))
+		} else {
+			fmt.Printf("Deleted %d unreachable object(s), packed %d object(s), and kept %d recently unreachable object(s).\n", len(summary.Pruned), summary.Packed, len(summary.Kept))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().Duration(gcPruneFlag, gcPruneDefault, gcPruneUsage)
+	gcCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, "List what would be deleted, without changing anything.")
+}
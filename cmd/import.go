@@ -0,0 +1,158 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/statement"
+)
+
+const (
+	importAccountFlag      = "account"
+	importAccountShorthand = "A"
+	importAccountUsage     = "The account that the statement belongs to, for example \"accounts/checking\"."
+)
+
+const (
+	importBudgetFlag      = "budget"
+	importBudgetShorthand = "B"
+	importBudgetDefault   = "budget/unsorted"
+	importBudgetUsage     = "The budget that imported transactions should be drawn from until they are categorized."
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Creates transactions from a statement provided by a financial institution.",
+	Long: `Reads a statement downloaded from a bank or credit card company, and commits one
+transaction for each entry it contains. Each transaction adjusts the statement's
+account and a single budget by the same amount, so that accounts and budgets
+continue to balance. Once imported, transactions can be re-categorized using the
+usual "transfer" and "commit" commands.
+
+Entries that have a bank record ID which already appears in the history of the
+current branch are skipped, so it is safe to import overlapping statements.
+
+The index must not have any uncommitted changes when importing.`,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.PersistentFlags().StringP(importAccountFlag, importAccountShorthand, "", importAccountUsage)
+	importCmd.PersistentFlags().StringP(importBudgetFlag, importBudgetShorthand, importBudgetDefault, importBudgetUsage)
+	importCmd.PersistentFlags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, dryrunUsage)
+}
+
+// importEntries commits one transaction for each statement entry that hasn't already been recorded, debiting or
+// crediting the named account and budget by the amount of each entry. The caller must be holding the repository's lock.
+func importEntries(ctx context.Context, cmd *cobra.Command, account, budget string, entries []statement.Entry) (err error) {
+	dryrun, err := cmd.Flags().GetBool(dryrunFlag)
+	if err != nil {
+		return err
+	}
+
+	root, repo, headID, current, err := openCleanIndex(ctx, "importing")
+	if err != nil {
+		return err
+	}
+
+	seen, err := knownBankRecordIDs(ctx, repo, headID)
+	if err != nil {
+		return err
+	}
+
+	batch := &batchCommit{root: root, repo: repo}
+	defer func() {
+		if closeErr := batch.Close(ctx); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var imported, skipped uint
+	for _, entry := range entries {
+		if entry.RecordID != "" {
+			if _, ok := seen[entry.RecordID]; ok {
+				skipped++
+				continue
+			}
+			seen[entry.RecordID] = struct{}{}
+		}
+
+		next := current.DeepCopy()
+		err = index.AdjustState(&next, account, entry.Amount)
+		if err != nil {
+			return err
+		}
+		err = index.AdjustState(&next, budget, entry.Amount)
+		if err != nil {
+			return err
+		}
+
+		transaction := envelopes.Transaction{
+			State:       &next,
+			PostedTime:  entry.PostedTime,
+			ActualTime:  entry.ActualTime,
+			EnteredTime: time.Now(),
+			Amount:      entry.Amount,
+			Merchant:    entry.Merchant,
+			Comment:     entry.Comment,
+			RecordID:    entry.RecordID,
+		}
+
+		if dryrun {
+			err = format.ConcisePrintTransaction(ctx, cmd.OutOrStdout(), transaction)
+		} else {
+			err = batch.Commit(ctx, transaction)
+		}
+		if err != nil {
+			return err
+		}
+		current = next
+		imported++
+	}
+
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Imported %d transaction(s), skipped %d already recorded.\n", imported, skipped)
+	return err
+}
+
+// knownBankRecordIDs finds every envelopes.BankRecordID that has been recorded in the history of a Transaction.
+func knownBankRecordIDs(ctx context.Context, loader persist.Loader, head envelopes.ID) (map[envelopes.BankRecordID]struct{}, error) {
+	retval := make(map[envelopes.BankRecordID]struct{})
+	if head.Equal(envelopes.ID{}) {
+		return retval, nil
+	}
+
+	walker := persist.Walker{Loader: loader}
+	err := walker.Walk(ctx, func(_ context.Context, _ envelopes.ID, transaction envelopes.Transaction) error {
+		if transaction.RecordID != "" {
+			retval[transaction.RecordID] = struct{}{}
+		}
+		return nil
+	}, head)
+
+	return retval, err
+}
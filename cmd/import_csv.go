@@ -0,0 +1,303 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/statement"
+)
+
+const (
+	csvPostedTimeColumnFlag  = "posted-time-column"
+	csvPostedTimeColumnUsage = "The header or 1-based position of the column holding the time a transaction posted. Defaults to the actual time."
+)
+
+const (
+	csvActualTimeColumnFlag  = "actual-time-column"
+	csvActualTimeColumnUsage = "The header or 1-based position of the column holding the time a transaction occurred."
+)
+
+const (
+	csvAmountColumnFlag  = "amount-column"
+	csvAmountColumnUsage = "The header or 1-based position of the column holding the amount of a transaction."
+)
+
+const (
+	csvMerchantColumnFlag  = "merchant-column"
+	csvMerchantColumnUsage = "The header or 1-based position of the column holding the merchant of a transaction."
+)
+
+const (
+	csvBankRecordIDColumnFlag  = "bank-record-id-column"
+	csvBankRecordIDColumnUsage = "The header or 1-based position of the column holding the bank's ID for a transaction."
+)
+
+const (
+	csvCommentColumnFlag  = "comment-column"
+	csvCommentColumnUsage = "The header or 1-based position of the column holding notes about a transaction."
+)
+
+const (
+	csvTimeFormatFlag  = "time-format"
+	csvTimeFormatUsage = "The layout of times in the statement, written as the reference time \"01/02/2006 15:04:05\" would be."
+)
+
+const (
+	csvNoHeaderFlag  = "no-header"
+	csvNoHeaderUsage = "The first row of the statement is a transaction, not a list of column names."
+)
+
+const (
+	csvInvertAmountFlag  = "invert-amount"
+	csvInvertAmountUsage = "The statement shows money leaving the account as a positive amount."
+)
+
+const (
+	csvAssetFlag  = "asset"
+	csvAssetUsage = "The asset type amounts are denominated in when the statement doesn't say."
+)
+
+var importCSVCmd = &cobra.Command{
+	Use:   "csv {file}",
+	Short: "Creates transactions from a CSV statement.",
+	Long: `Creates transactions from a CSV statement.
+
+Every bank lays out its CSV exports differently, so the first import for an
+account needs to describe which column holds each field. For example:
+
+  baronial import csv export.csv -A accounts/credit-union/checking \
+    --posted-time-column "Posting Date" --amount-column Amount \
+    --merchant-column Description --bank-record-id-column "Transaction ID"
+
+The column mapping is saved in the repository, so later imports for the same
+account only need the file and the account. Passing any of the mapping flags
+again updates the saved mapping.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		account, err := cmd.Flags().GetString(importAccountFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if account == "" {
+			logrus.Fatalf("the --%s flag is required", importAccountFlag)
+		}
+
+		budget, err := cmd.Flags().GetString(importBudgetFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		// The lock is held until the mapping has been saved, not just while importing.
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		var mapping statement.CSVMapping
+		err = CSVMappingUnstow(ctx, repoLoc, account, &mapping)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Fatal(err)
+		}
+
+		var changed bool
+		changed, err = updateCSVMappingFromFlags(cmd, &mapping)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if mapping.Amount == "" {
+			logrus.Fatalf("no column mapping is saved for %q, at least --%s must be provided", account, csvAmountColumnFlag)
+		}
+
+		if mapping.PostedTime == "" && mapping.ActualTime == "" {
+			logrus.Fatalf("either --%s or --%s must be provided", csvPostedTimeColumnFlag, csvActualTimeColumnFlag)
+		}
+
+		handle, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		defer handle.Close()
+
+		entries, err := statement.ReadCSV(ctx, handle, mapping)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = importEntries(ctx, cmd, account, budget, entries)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if changed {
+			err = CSVMappingStow(ctx, repoLoc, account, mapping)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importCSVCmd)
+
+	importCSVCmd.Flags().String(csvPostedTimeColumnFlag, "", csvPostedTimeColumnUsage)
+	importCSVCmd.Flags().String(csvActualTimeColumnFlag, "", csvActualTimeColumnUsage)
+	importCSVCmd.Flags().String(csvAmountColumnFlag, "", csvAmountColumnUsage)
+	importCSVCmd.Flags().String(csvMerchantColumnFlag, "", csvMerchantColumnUsage)
+	importCSVCmd.Flags().String(csvBankRecordIDColumnFlag, "", csvBankRecordIDColumnUsage)
+	importCSVCmd.Flags().String(csvCommentColumnFlag, "", csvCommentColumnUsage)
+	importCSVCmd.Flags().String(csvTimeFormatFlag, "", csvTimeFormatUsage)
+	importCSVCmd.Flags().Bool(csvNoHeaderFlag, false, csvNoHeaderUsage)
+	importCSVCmd.Flags().Bool(csvInvertAmountFlag, false, csvInvertAmountUsage)
+	importCSVCmd.Flags().String(csvAssetFlag, string(envelopes.DefaultAsset), csvAssetUsage)
+}
+
+// updateCSVMappingFromFlags overwrites the fields of a mapping which were explicitly set on the command line, and
+// reports whether there were any.
+func updateCSVMappingFromFlags(cmd *cobra.Command, mapping *statement.CSVMapping) (bool, error) {
+	changed := false
+
+	stringFields := []struct {
+		flag   string
+		target *string
+	}{
+		{csvPostedTimeColumnFlag, &mapping.PostedTime},
+		{csvActualTimeColumnFlag, &mapping.ActualTime},
+		{csvAmountColumnFlag, &mapping.Amount},
+		{csvMerchantColumnFlag, &mapping.Merchant},
+		{csvBankRecordIDColumnFlag, &mapping.RecordID},
+		{csvCommentColumnFlag, &mapping.Comment},
+		{csvTimeFormatFlag, &mapping.TimeFormat},
+	}
+
+	for _, field := range stringFields {
+		if !cmd.Flags().Changed(field.flag) {
+			continue
+		}
+
+		value, err := cmd.Flags().GetString(field.flag)
+		if err != nil {
+			return false, err
+		}
+		*field.target = value
+		changed = true
+	}
+
+	boolFields := []struct {
+		flag   string
+		target *bool
+	}{
+		{csvNoHeaderFlag, &mapping.NoHeader},
+		{csvInvertAmountFlag, &mapping.InvertAmount},
+	}
+
+	for _, field := range boolFields {
+		if !cmd.Flags().Changed(field.flag) {
+			continue
+		}
+
+		value, err := cmd.Flags().GetBool(field.flag)
+		if err != nil {
+			return false, err
+		}
+		*field.target = value
+		changed = true
+	}
+
+	if cmd.Flags().Changed(csvAssetFlag) {
+		value, err := cmd.Flags().GetString(csvAssetFlag)
+		if err != nil {
+			return false, err
+		}
+		mapping.Asset = envelopes.AssetType(value)
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// CSVMappingStow saves the column mapping that should be used when importing CSV statements for an account.
+func CSVMappingStow(_ context.Context, repoLoc string, account string, mapping statement.CSVMapping) error {
+	const filePermissions = 0660
+	loc, err := getCSVMappingLoc(repoLoc, account)
+	if err != nil {
+		return err
+	}
+
+	toWrite, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal csv mapping: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(loc), filePermissions|os.ModeDir|0110)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(loc, toWrite, filePermissions)
+	if err != nil {
+		return fmt.Errorf("couldn't write csv mapping file: %w", err)
+	}
+	return nil
+}
+
+// CSVMappingUnstow reads the column mapping that was previously saved for an account. If none has been saved, the
+// error satisfies os.IsNotExist.
+func CSVMappingUnstow(_ context.Context, repoLoc string, account string, destination *statement.CSVMapping) error {
+	loc, err := getCSVMappingLoc(repoLoc, account)
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(loc)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(contents, destination)
+	if err != nil {
+		return fmt.Errorf("couldn't parse the csv mapping json: %w", err)
+	}
+	return nil
+}
+
+func getCSVMappingLoc(repoLoc string, account string) (string, error) {
+	dir, name := index.SplitEntityName(account)
+	if dir != index.AccountsDir || name == "" {
+		return "", fmt.Errorf("%q does not name an account", account)
+	}
+	return filepath.Join(repoLoc, "import", "csv", filepath.FromSlash(name)+".json"), nil
+}
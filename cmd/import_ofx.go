@@ -0,0 +1,74 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/statement"
+)
+
+var importOFXCmd = &cobra.Command{
+	Use:     "ofx {file}",
+	Aliases: []string{"qfx"},
+	Short:   "Creates transactions from an OFX or QFX statement.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		account, err := cmd.Flags().GetString(importAccountFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if account == "" {
+			logrus.Fatalf("the --%s flag is required", importAccountFlag)
+		}
+
+		budget, err := cmd.Flags().GetString(importBudgetFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		handle, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		defer handle.Close()
+
+		entries, err := statement.ReadOFX(ctx, handle)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		err = importEntries(ctx, cmd, account, budget, entries)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importOFXCmd)
+}
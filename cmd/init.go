@@ -17,14 +17,15 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 var initCmd = &cobra.Command{
@@ -39,23 +40,12 @@ var initCmd = &cobra.Command{
 
 		const initialBranch = persist.DefaultBranch
 
-		dirsToCreate := []string{
-			index.RepoName,
-			index.AccountsDir,
-			index.BudgetDir,
-		}
-
-		for _, dir := range dirsToCreate {
-			const dirCreationPermissions = 0750
-			err := os.Mkdir(dir, os.FileMode(dirCreationPermissions))
-			if os.IsExist(err) {
-				// Intentionally Left Blank
-			} else if err != nil {
-				logrus.Fatal(initCmdFailurePrefix, err)
-			}
+		err := createIndexDirs(".")
+		if err != nil {
+			logrus.Fatal(initCmdFailurePrefix, err)
 		}
 
-		repo, err := filesystem.OpenRepositoryWithCache(ctx, index.RepoName, 10000)
+		repo, err := pack.OpenRepositoryWithCache(ctx, index.RepoName, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -72,6 +62,26 @@ var initCmd = &cobra.Command{
 	},
 }
 
+// createIndexDirs makes the directories that every index needs, if they don't already exist.
+func createIndexDirs(root string) error {
+	dirsToCreate := []string{
+		index.RepoName,
+		index.AccountsDir,
+		index.BudgetDir,
+	}
+
+	for _, dir := range dirsToCreate {
+		const dirCreationPermissions = 0750
+		err := os.Mkdir(filepath.Join(root, dir), os.FileMode(dirCreationPermissions))
+		if os.IsExist(err) {
+			// Intentionally Left Blank
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
 }
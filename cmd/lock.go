@@ -0,0 +1,80 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/lock"
+)
+
+const (
+	waitFlag    = "wait"
+	waitDefault = false
+	waitUsage   = `If another baronial process is changing the repository, wait for it to finish instead of failing. Use "--timeout" to limit how long to wait.`
+)
+
+// lockRepository takes the lock on the repository that contains dirname, so that no other baronial process changes it
+// at the same time. If the lock can't be taken, the command fails. The returned function releases the lock. The lock
+// is also released if the command fails with logrus.Fatal, which wouldn't run deferred calls.
+func lockRepository(ctx context.Context, cmd *cobra.Command, dirname string) func() {
+	root, err := index.RootDirectory(dirname)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	wait, err := cmd.Flags().GetBool(waitFlag)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	var held *lock.Lock
+	if wait {
+		held, err = lock.Acquire(ctx, repoLoc)
+	} else {
+		held, err = lock.TryAcquire(repoLoc)
+		if errors.As(err, new(lock.ErrLocked)) {
+			logrus.Fatalf("%v, use \"--%s\" to wait for it to finish", err, waitFlag)
+		}
+	}
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	release := func() {
+		if err := held.Release(); err != nil {
+			logrus.Warnf("couldn't release the lock on the repository: %v", err)
+		}
+	}
+	logrus.RegisterExitHandler(release)
+
+	// Now that nobody else can be in the middle of one, finish any checkout that was interrupted.
+	err = index.RecoverCheckout(root)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	return release
+}
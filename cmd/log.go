@@ -18,25 +18,65 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/graph"
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+const (
+	logFormatFlag  = "format"
+	logFormatUsage = "How to write each transaction. Either \"oneline\", \"short\", \"full\", \"ledger\", or a Go text/template."
+)
+
+const (
+	logGraphFlag    = "graph"
+	logGraphDefault = false
+	logGraphUsage   = "Draw how each transaction is related to the others, showing where branches diverged and were merged."
+)
+
+const (
+	logAllFlag    = "all"
+	logAllDefault = false
+	logAllUsage   = "Include the history of every branch, not just the one that is checked out."
 )
 
 var logCmd = &cobra.Command{
 	Use:   "log [{account | budget}...]",
 	Short: "Lists an overview of each transaction.",
-	Long:  "",
+	Long: `Lists an overview of each transaction, starting with the most recent.
+
+Providing accounts or budgets limits the list to transactions that impacted at
+least one of them. The flags below narrow it further, and a transaction must
+satisfy all of them to be shown. For example, to find a charge from Amazon in
+March:
+
+    baronial log --merchant amazon --since 2026-03-01 --until 2026-03-31
+
+Use --format to choose how each transaction is written. It accepts one of the
+presets "oneline", "short", "full", or "ledger", or a Go text/template that is
+given the fields of a transaction, like:
+
+    baronial log --format '{{date "Jan 2" .PostedTime}}  {{.Merchant}}  {{.Amount}}'
+
+Along with the fields of a transaction, templates may use .ID, .Impact, and the
+functions date, short, ids, budgets, postings, and details.
+
+Use --graph to see where branches diverged and were merged back together, and
+--all to include every branch instead of only the one checked out.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		return nil
 	},
@@ -52,7 +92,7 @@ var logCmd = &cobra.Command{
 		}
 
 		var repo persist.RepositoryReader
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, filepath.Join(root, index.RepoName), 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, filepath.Join(root, index.RepoName), 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -69,34 +109,244 @@ var logCmd = &cobra.Command{
 			return
 		}
 
-		walker := persist.Walker{Loader: repo}
-		err = walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
-			impact, err := persist.LoadImpact(ctx, repo, transaction)
+		outputFormat, err := getOutputFormat(cmd)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+
+		filter, err := newLogFilter(cmd, args)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+
+		maxCount, err := cmd.Flags().GetInt(logMaxCountFlag)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+		if maxCount < 0 {
+			logrus.Errorf("--%s must not be negative", logMaxCountFlag)
+			return
+		}
+
+		var transactionTemplate *format.TransactionTemplate
+		if cmd.Flags().Changed(logFormatFlag) {
+			if outputFormat != format.OutputText {
+				logrus.Errorf("--%s can only be used with text output", logFormatFlag)
+				return
+			}
+
+			var rawTemplate string
+			rawTemplate, err = cmd.Flags().GetString(logFormatFlag)
 			if err != nil {
-				return err
+				logrus.Error(err)
+				return
 			}
 
-			if len(args) == 0 || containsEntity(impact, args...) {
-				err = format.ConcisePrintTransaction(ctx, cmd.OutOrStdout(), transaction)
-				if err != nil {
-					if cast, ok := err.(*os.PathError); ok {
-						if cast.Path == "|1" {
-							return nil
-						}
-					}
-					return err
-				}
+			transactionTemplate, err = format.ParseTransactionTemplate(rawTemplate)
+			if err != nil {
+				logrus.Error(err)
+				return
 			}
-			return nil
-		}, currentID)
+		}
+
+		graphed, err := cmd.Flags().GetBool(logGraphFlag)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+		if graphed && outputFormat != format.OutputText {
+			logrus.Errorf("--%s can only be used with text output", logGraphFlag)
+			return
+		}
 
+		all, err := cmd.Flags().GetBool(logAllFlag)
 		if err != nil {
 			logrus.Error(err)
 			return
 		}
+
+		heads := []envelopes.ID{currentID}
+		if all {
+			heads, err = getBranchHeads(ctx, repo, heads...)
+			if err != nil {
+				logrus.Error(err)
+				return
+			}
+		}
+
+		printTransaction := func(output io.Writer, transaction envelopes.Transaction) error {
+			if transactionTemplate != nil {
+				return transactionTemplate.Execute(ctx, output, repo, transaction)
+			}
+			return format.ConcisePrintTransaction(ctx, output, transaction)
+		}
+
+		var documents []format.TransactionDocument
+		shown := 0
+
+		if graphed {
+			err = writeLogGraph(ctx, cmd.OutOrStdout(), repo, filter, maxCount, printTransaction, heads...)
+		} else {
+			walker := persist.Walker{Loader: repo}
+			err = walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+				if maxCount > 0 && shown >= maxCount {
+					return errLogFinished
+				}
+
+				var impact envelopes.Impact
+				if filter.needsImpact() {
+					impact, err = persist.LoadImpact(ctx, repo, transaction)
+					if err != nil {
+						return err
+					}
+				}
+
+				if filter.matches(transaction, impact) {
+					shown++
+					if outputFormat != format.OutputText {
+						documents = append(documents, format.NewTransactionDocument(transaction))
+						return nil
+					}
+
+					return printTransaction(cmd.OutOrStdout(), transaction)
+				}
+				return nil
+			}, heads...)
+		}
+
+		if isClosedPager(err) {
+			err = nil
+		}
+
+		if err != nil && !errors.Is(err, errLogFinished) {
+			logrus.Error(err)
+			return
+		}
+
+		if outputFormat != format.OutputText {
+			if documents == nil {
+				documents = []format.TransactionDocument{}
+			}
+
+			err = format.WriteDocument(cmd.OutOrStdout(), outputFormat, documents)
+			if err != nil {
+				logrus.Error(err)
+				return
+			}
+		}
 	},
 }
 
+// getBranchHeads adds the transaction at the end of every branch to a list of heads, skipping any that are already
+// present.
+func getBranchHeads(ctx context.Context, repo persist.BareRepositoryReader, heads ...envelopes.ID) ([]envelopes.ID, error) {
+	branches, err := repo.ListBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[envelopes.ID]struct{}, len(heads))
+	for _, head := range heads {
+		seen[head] = struct{}{}
+	}
+
+	for branch := range branches {
+		head, err := repo.ReadBranch(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[head]; ok || isEmptyID(head) {
+			continue
+		}
+		seen[head] = struct{}{}
+		heads = append(heads, head)
+	}
+	return heads, nil
+}
+
+// writeLogGraph prints the transactions reachable from the heads that satisfy a filter, each beside a drawing of how
+// it is related to the others. Transactions that are filtered out are skipped over, connecting those that remain to
+// their nearest ancestors that are shown.
+func writeLogGraph(
+	ctx context.Context,
+	output io.Writer,
+	repo persist.Loader,
+	filter *logFilter,
+	maxCount int,
+	printTransaction func(io.Writer, envelopes.Transaction) error,
+	heads ...envelopes.ID) error {
+
+	transactions := make(map[envelopes.ID]envelopes.Transaction)
+	nodes := make(map[envelopes.ID]graph.Node)
+	kept := make(map[envelopes.ID]bool)
+
+	walker := persist.Walker{Loader: repo}
+	err := walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		var impact envelopes.Impact
+		var err error
+		if filter.needsImpact() {
+			impact, err = persist.LoadImpact(ctx, repo, transaction)
+			if err != nil {
+				return err
+			}
+		}
+
+		node := graph.Node{ID: id, Time: transaction.EnteredTime}
+		for _, parent := range transaction.Parents {
+			if !isEmptyID(parent) {
+				node.Parents = append(node.Parents, parent)
+			}
+		}
+		nodes[id] = node
+		if filter.matches(transaction, impact) {
+			kept[id] = true
+			transactions[id] = transaction
+		}
+		return nil
+	}, heads...)
+	if err != nil {
+		return err
+	}
+
+	nodes = graph.Simplify(nodes, func(id envelopes.ID) bool {
+		return kept[id]
+	})
+
+	drawing := &graph.Graph{}
+	buf := &bytes.Buffer{}
+	for i, node := range graph.Sort(nodes) {
+		if maxCount > 0 && i >= maxCount {
+			break
+		}
+
+		buf.Reset()
+		err = printTransaction(buf, transactions[node.ID])
+		if err != nil {
+			return err
+		}
+
+		err = drawing.Write(output, node, buf.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isClosedPager determines whether an error came from writing to a pager that the user has already closed, which
+// isn't worth reporting.
+func isClosedPager(err error) bool {
+	var cast *os.PathError
+	return errors.As(err, &cast) && cast.Path == "|1"
+}
+
+// errLogFinished stops walking history once "log" has shown as many transactions as it was asked to.
+var errLogFinished = errors.New("enough transactions have been shown")
+
 // containsEntity inspects an Impact, to see if any of the entities provided were impacted by a transaction.
 func containsEntity(diff envelopes.Impact, entities ...string) bool {
 	for _, entity := range entities {
@@ -159,4 +409,18 @@ func isEmptyID(subject envelopes.ID) bool {
 
 func init() {
 	rootCmd.AddCommand(logCmd)
+
+	supportStructuredOutput(logCmd)
+	logCmd.Flags().String(logFormatFlag, "", logFormatUsage)
+	logCmd.Flags().Bool(logGraphFlag, logGraphDefault, logGraphUsage)
+	logCmd.Flags().Bool(logAllFlag, logAllDefault, logAllUsage)
+	logCmd.Flags().String(logSinceFlag, "", logSinceUsage)
+	logCmd.Flags().String(logUntilFlag, "", logUntilUsage)
+	logCmd.Flags().String(logTimeFieldFlag, logTimeFieldDefault, logTimeFieldUsage)
+	logCmd.Flags().StringP(merchantFlag, merchantShorthand, "", logMerchantUsage)
+	logCmd.Flags().StringP(commentFlag, commentShorthand, "", logCommentUsage)
+	logCmd.Flags().String(logMinAmountFlag, "", logMinAmountUsage)
+	logCmd.Flags().String(logMaxAmountFlag, "", logMaxAmountUsage)
+	logCmd.Flags().String(logRecordIDFlag, "", logRecordIDUsage)
+	logCmd.Flags().IntP(logMaxCountFlag, logMaxCountShorthand, logMaxCountDefault, logMaxCountUsage)
 }
@@ -0,0 +1,256 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logSinceFlag  = "since"
+	logSinceUsage = "Only show transactions on or after this date."
+)
+
+const (
+	logUntilFlag  = "until"
+	logUntilUsage = "Only show transactions on or before this date."
+)
+
+const (
+	logTimeFieldFlag    = "time-field"
+	logTimeFieldDefault = logTimePosted
+	logTimeFieldUsage   = "Which time --since and --until apply to. One of: \"posted\", \"actual\", or \"entered\"."
+)
+
+const (
+	logTimePosted  = "posted"
+	logTimeActual  = "actual"
+	logTimeEntered = "entered"
+)
+
+const (
+	logMerchantUsage = "Only show transactions whose merchant matches this regular expression. Case is ignored."
+	logCommentUsage  = "Only show transactions whose comment matches this regular expression. Case is ignored."
+)
+
+const (
+	logMinAmountFlag  = "min-amount"
+	logMinAmountUsage = "Only show transactions moving at least this much of each asset, regardless of direction."
+)
+
+const (
+	logMaxAmountFlag  = "max-amount"
+	logMaxAmountUsage = "Only show transactions moving at most this much of each asset, regardless of direction."
+)
+
+const (
+	logRecordIDFlag  = "record-id"
+	logRecordIDUsage = "Only show the transaction with this ID from a financial institution."
+)
+
+const (
+	logMaxCountFlag      = "max-count"
+	logMaxCountShorthand = "n"
+	logMaxCountDefault   = 0
+	logMaxCountUsage     = "Stop after showing this many transactions. Zero shows them all."
+)
+
+// logFilter decides which transactions "log" shows. A transaction is only shown if it satisfies every criteria that
+// was provided.
+type logFilter struct {
+	entities  []string
+	since     time.Time
+	until     time.Time
+	timeOf    func(envelopes.Transaction) time.Time
+	merchant  *regexp.Regexp
+	comment   *regexp.Regexp
+	minAmount envelopes.Balance
+	maxAmount envelopes.Balance
+	recordID  envelopes.BankRecordID
+}
+
+// newLogFilter reads the flags of "log" into a logFilter. Any positional arguments are treated as the accounts or
+// budgets a transaction must impact.
+func newLogFilter(cmd *cobra.Command, args []string) (*logFilter, error) {
+	var err error
+	retval := &logFilter{
+		entities: args,
+	}
+
+	if cmd.Flags().Changed(logSinceFlag) {
+		retval.since, err = getTimeFlag(cmd, logSinceFlag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Flags().Changed(logUntilFlag) {
+		retval.until, err = getTimeFlag(cmd, logUntilFlag)
+		if err != nil {
+			return nil, err
+		}
+		retval.until = endOfDay(retval.until)
+	}
+
+	timeField, err := cmd.Flags().GetString(logTimeFieldFlag)
+	if err != nil {
+		return nil, err
+	}
+	retval.timeOf, err = getTransactionTime(timeField)
+	if err != nil {
+		return nil, err
+	}
+
+	retval.merchant, err = getRegexpFlag(cmd, merchantFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	retval.comment, err = getRegexpFlag(cmd, commentFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	retval.minAmount, err = getBalanceFlag(cmd, logMinAmountFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	retval.maxAmount, err = getBalanceFlag(cmd, logMaxAmountFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRecordID, err := cmd.Flags().GetString(logRecordIDFlag)
+	if err != nil {
+		return nil, err
+	}
+	retval.recordID = envelopes.BankRecordID(rawRecordID)
+
+	return retval, nil
+}
+
+// needsImpact indicates whether matches will inspect the Impact it is given, so that callers can skip loading it.
+func (f logFilter) needsImpact() bool {
+	return len(f.entities) > 0
+}
+
+// matches determines whether a transaction, which had the given impact, should be shown.
+func (f logFilter) matches(transaction envelopes.Transaction, impact envelopes.Impact) bool {
+	if len(f.entities) > 0 && !containsEntity(impact, f.entities...) {
+		return false
+	}
+
+	if !f.since.IsZero() || !f.until.IsZero() {
+		when := f.timeOf(transaction)
+		if when.IsZero() {
+			return false
+		}
+		if !f.since.IsZero() && when.Before(f.since) {
+			return false
+		}
+		if !f.until.IsZero() && when.After(f.until) {
+			return false
+		}
+	}
+
+	if f.merchant != nil && !f.merchant.MatchString(transaction.Merchant) {
+		return false
+	}
+
+	if f.comment != nil && !f.comment.MatchString(transaction.Comment) {
+		return false
+	}
+
+	if f.recordID != "" && !f.recordID.Equal(transaction.RecordID) {
+		return false
+	}
+
+	for asset, bound := range f.minAmount {
+		if magnitude(transaction.Amount, asset).Cmp(bound) < 0 {
+			return false
+		}
+	}
+
+	for asset, bound := range f.maxAmount {
+		if magnitude(transaction.Amount, asset).Cmp(bound) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// magnitude finds the absolute value of one asset in a Balance, treating a missing asset as zero.
+func magnitude(subject envelopes.Balance, asset envelopes.AssetType) *big.Rat {
+	retval := new(big.Rat)
+	if val, ok := subject[asset]; ok && val != nil {
+		retval.Abs(val)
+	}
+	return retval
+}
+
+func getTransactionTime(field string) (func(envelopes.Transaction) time.Time, error) {
+	switch field {
+	case logTimePosted:
+		return func(transaction envelopes.Transaction) time.Time { return transaction.PostedTime }, nil
+	case logTimeActual:
+		return func(transaction envelopes.Transaction) time.Time { return transaction.ActualTime }, nil
+	case logTimeEntered:
+		return func(transaction envelopes.Transaction) time.Time { return transaction.EnteredTime }, nil
+	default:
+		return nil, fmt.Errorf("unrecognized time %q, must be one of %q, %q, or %q", field, logTimePosted, logTimeActual, logTimeEntered)
+	}
+}
+
+func getRegexpFlag(cmd *cobra.Command, flag string) (*regexp.Regexp, error) {
+	raw, err := cmd.Flags().GetString(flag)
+	if err != nil || raw == "" {
+		return nil, err
+	}
+
+	retval, err := regexp.Compile("(?i)" + raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse --%s: %w", flag, err)
+	}
+	return retval, nil
+}
+
+func getBalanceFlag(cmd *cobra.Command, flag string) (envelopes.Balance, error) {
+	raw, err := cmd.Flags().GetString(flag)
+	if err != nil || raw == "" {
+		return nil, err
+	}
+
+	retval, err := envelopes.ParseBalance([]byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse --%s: %w", flag, err)
+	}
+	for _, val := range retval {
+		if val.Sign() < 0 {
+			return nil, fmt.Errorf("--%s must not be negative, amounts are compared regardless of direction", flag)
+		}
+	}
+	return retval, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestLogFilter_matches(t *testing.T) {
+	postedTime, _ := getTransactionTime(logTimePosted)
+	enteredTime, _ := getTransactionTime(logTimeEntered)
+
+	subject := envelopes.Transaction{
+		PostedTime:  time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC),
+		EnteredTime: time.Date(2026, time.April, 2, 0, 0, 0, 0, time.UTC),
+		Amount:      envelopes.Balance{"USD": big.NewRat(-4299, 100)},
+		Merchant:    "Amazon.com",
+		Comment:     "Replacement charger",
+		RecordID:    "20260314-0042",
+	}
+
+	impact := envelopes.Impact{
+		Accounts: envelopes.Accounts{"checking": envelopes.Balance{"USD": big.NewRat(-4299, 100)}},
+	}
+
+	testCases := []struct {
+		name     string
+		filter   logFilter
+		expected bool
+	}{
+		{"empty", logFilter{timeOf: postedTime}, true},
+		{"account", logFilter{timeOf: postedTime, entities: []string{"accounts/checking"}}, true},
+		{"other account", logFilter{timeOf: postedTime, entities: []string{"accounts/savings"}}, false},
+		{"in range", logFilter{timeOf: postedTime, since: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), until: endOfDay(time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC))}, true},
+		{"before range", logFilter{timeOf: postedTime, since: time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)}, false},
+		{"after range", logFilter{timeOf: postedTime, until: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)}, false},
+		{"entered in range", logFilter{timeOf: enteredTime, since: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{"merchant", logFilter{timeOf: postedTime, merchant: regexp.MustCompile("(?i)^amazon")}, true},
+		{"other merchant", logFilter{timeOf: postedTime, merchant: regexp.MustCompile("(?i)target")}, false},
+		{"comment", logFilter{timeOf: postedTime, comment: regexp.MustCompile("(?i)charger")}, true},
+		{"record", logFilter{timeOf: postedTime, recordID: "20260314-0042"}, true},
+		{"other record", logFilter{timeOf: postedTime, recordID: "20260314-0043"}, false},
+		{"at least", logFilter{timeOf: postedTime, minAmount: envelopes.Balance{"USD": big.NewRat(40, 1)}}, true},
+		{"too small", logFilter{timeOf: postedTime, minAmount: envelopes.Balance{"USD": big.NewRat(50, 1)}}, false},
+		{"at most", logFilter{timeOf: postedTime, maxAmount: envelopes.Balance{"USD": big.NewRat(4299, 100)}}, true},
+		{"too large", logFilter{timeOf: postedTime, maxAmount: envelopes.Balance{"USD": big.NewRat(40, 1)}}, false},
+		{"other asset", logFilter{timeOf: postedTime, minAmount: envelopes.Balance{"EUR": big.NewRat(1, 1)}}, false},
+		{"all", logFilter{timeOf: postedTime, merchant: regexp.MustCompile("(?i)amazon"), minAmount: envelopes.Balance{"USD": big.NewRat(40, 1)}, recordID: "nope"}, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.filter.matches(subject, impact); got != tc.expected {
+			t.Logf("%s\n\tgot:  %v\n\twant: %v", tc.name, got, tc.expected)
+			t.Fail()
+		}
+	}
+}
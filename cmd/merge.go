@@ -20,15 +20,20 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/merge"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/remote"
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -37,11 +42,42 @@ type MergeParameters struct {
 	Comment     string            `json:"comment,omitempty"`
 	Parents     []envelopes.ID    `json:"parent_ids"`
 	ParentNames []persist.RefSpec `json:"parent_names"`
+	Conflicts   []merge.Conflict  `json:"conflicts,omitempty"`
+	IndexID     envelopes.ID      `json:"index_id,omitempty"`
 }
 
+const (
+	mergeStatusFlag    = "status"
+	mergeStatusDefault = false
+	mergeStatusUsage   = "List the accounts and budgets that the in-progress merge couldn't combine on its own."
+)
+
+const mergeAbortUsage = "Stop the in-progress merge, restoring the index to the balances from before it started."
+
+const (
+	mergeResolveFlag    = "resolve"
+	mergeResolveDefault = false
+	mergeResolveUsage   = "Mark the named accounts and budgets as resolved, once their balances in the index are correct."
+)
+
 var mergeCmd = &cobra.Command{
-	Use:  "merge {refspec} [refspec]...",
-	Args: cobra.MinimumNArgs(1),
+	Use:   "merge {refspec} [refspec]...",
+	Short: "Combines the balances of other branches with the one that is checked out.",
+	Long: `Combines the balances of other branches with the one that is checked out, and
+writes the result to the index. Each account and budget is merged on its own,
+relative to the most recent transaction the branches have in common: when only
+one branch changed it, that change is kept.
+
+When more than one branch changed the same account or budget in different ways,
+it is a conflict. The index will hold the common balance plus every branch's
+change to it, so no spending is lost, but it should be checked. List conflicts
+with "merge --status", fix their balances in the index, then mark each one with
+"merge --resolve {path}". The merge can't be committed until all are resolved.
+
+To give up on a merge, use "merge --abort". If the index has been edited since
+the merge started, including to resolve conflicts, aborting discards those edits
+too, so it is refused unless "--force" is also given.`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := RootContext(cmd)
 		defer cancel()
@@ -52,72 +88,317 @@ var mergeCmd = &cobra.Command{
 		}
 		repoLoc := filepath.Join(root, index.RepoName)
 
-		var repo persist.RepositoryReader
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		status, err := cmd.Flags().GetBool(mergeStatusFlag)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		var currentHead persist.RefSpec
-		currentHead, err = repo.Current(ctx)
+		resolve, err := cmd.Flags().GetBool(mergeResolveFlag)
 		if err != nil {
-			logrus.Fatal("couldn't read what's currently checked out because: ", err)
+			logrus.Fatal(err)
 		}
 
-		heads := append([]persist.RefSpec{currentHead}, stringsToRefSpecs(args)...)
-
-		var inProg bool
-		inProg, err = MergeIsInProgress(ctx, repoLoc)
+		abort, err := cmd.Flags().GetBool(abortFlag)
 		if err != nil {
-			logrus.Warn("couldn't see if previous merge is in progress because: ", err)
+			logrus.Fatal(err)
 		}
 
-		var mergeParams MergeParameters
+		if !status {
+			unlock := lockRepository(ctx, cmd, root)
+			defer unlock()
+		}
 
-		if inProg {
-			err = MergeUnstowProgress(ctx, repoLoc, &mergeParams)
+		switch {
+		case boolCount(status, resolve, abort) > 1:
+			logrus.Fatalf("only one of --%s, --%s, and --%s may be used at a time", mergeStatusFlag, mergeResolveFlag, abortFlag)
+		case abort:
+			if len(args) > 0 {
+				logrus.Fatalf("--%s doesn't accept any arguments", abortFlag)
+			}
+			var force bool
+			force, err = cmd.Flags().GetBool(forceFlag)
 			if err != nil {
-				logrus.Fatal("couldn't read the currently in-progress merge because: ", err)
+				logrus.Fatal(err)
 			}
-		}
-
-		mergeParams.ParentNames = append(mergeParams.ParentNames, heads...)
-		for _, head := range heads {
-			var id envelopes.ID
-			id, err = persist.Resolve(ctx, repo, head)
+			err = abortMerge(ctx, root, force)
 			if err != nil {
-				logrus.Fatalf("couldn't resolve head %q because: %v", head, err)
+				logrus.Fatal(err)
 			}
-
-			mergeParams.Parents = append(mergeParams.Parents, id)
+			return
+		case status:
+			if len(args) > 0 {
+				logrus.Fatalf("--%s doesn't accept any arguments", mergeStatusFlag)
+			}
+			err = printMergeStatus(ctx, os.Stdout, repoLoc)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			return
+		case resolve:
+			if len(args) == 0 {
+				logrus.Fatalf("--%s requires the path of at least one account or budget", mergeResolveFlag)
+			}
+			err = resolveConflicts(ctx, repoLoc, args)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			return
+		case len(args) == 0:
+			logrus.Fatal("at least one refspec to merge is required")
 		}
 
-		mergeParams.Comment = fmt.Sprintf(
-			"Merging %s into %s",
-			strings.Join(refSpecsToStrings(mergeParams.ParentNames)[1:], ", "),
-			string(mergeParams.ParentNames[0]),
-		)
-
-		err = MergeStowProgress(ctx, repoLoc, mergeParams)
+		var repo persist.RepositoryReader
+		repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		var merged envelopes.State
-		merged, err = persist.Merge(ctx, repo, heads)
+		var conflicts []merge.Conflict
+		conflicts, err = startMerge(ctx, repoLoc, remote.TrackingReader{RepositoryReader: repo, RepoLoc: repoLoc}, stringsToRefSpecs(args))
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		err = index.CheckoutState(ctx, &merged, repoLoc, 0660)
-		if err != nil {
-			logrus.Fatal(err)
+		if len(conflicts) > 0 {
+			printConflictSummary(os.Stdout, conflicts)
+			return
 		}
 
 		fmt.Println("Merge complete. Please check balances for accuracy, make any necessary reverts, and commit.")
 	},
 }
 
+// startMerge combines the balances of each RefSpec with those of the currently checked out Transaction, and writes
+// them to the index. The merge isn't finished until the result is committed. Accounts and budgets that couldn't be
+// combined cleanly are recorded with the merge's progress, and returned.
+func startMerge(ctx context.Context, repoLoc string, repo persist.RepositoryReader, refs []persist.RefSpec) ([]merge.Conflict, error) {
+	currentHead, err := repo.Current(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read what's currently checked out because: %w", err)
+	}
+
+	heads := append([]persist.RefSpec{currentHead}, refs...)
+
+	var inProg bool
+	inProg, err = MergeIsInProgress(ctx, repoLoc)
+	if err != nil {
+		logrus.Warn("couldn't see if previous merge is in progress because: ", err)
+	}
+
+	if inProg {
+		return nil, errors.New("a merge is already in progress, commit or abort it before starting another")
+	}
+
+	inProg, err = RevertIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return nil, err
+	} else if inProg {
+		return nil, errors.New("a revert is in progress, commit or abort it before merging")
+	}
+
+	var mergeParams MergeParameters
+	mergeParams.ParentNames = heads
+	for _, head := range heads {
+		var id envelopes.ID
+		id, err = persist.Resolve(ctx, repo, head)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't resolve head %q because: %w", head, err)
+		}
+
+		mergeParams.Parents = append(mergeParams.Parents, id)
+	}
+
+	mergeParams.Comment = fmt.Sprintf(
+		"Merging %s into %s",
+		strings.Join(refSpecsToStrings(mergeParams.ParentNames)[1:], ", "),
+		string(mergeParams.ParentNames[0]),
+	)
+
+	sides := make([]envelopes.State, len(mergeParams.Parents))
+	for i, id := range mergeParams.Parents {
+		sides[i], err = loadStateAt(ctx, repo, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Checking out the merged State would throw away anything that hasn't been committed yet.
+	err = requireCleanIndex(ctx, filepath.Dir(repoLoc), sides[0], "merging")
+	if err != nil {
+		return nil, err
+	}
+
+	var baseID envelopes.ID
+	baseID, err = persist.NearestCommonAncestorMany(ctx, repo, mergeParams.Parents)
+	if errors.As(err, new(persist.ErrNoCommonAncestor)) {
+		logrus.Warn("the branches have no transactions in common, so every difference between them will conflict")
+		baseID = envelopes.ID{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var base envelopes.State
+	base, err = loadStateAt(ctx, repo, baseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged envelopes.State
+	merged, mergeParams.Conflicts = merge.ThreeWay(base, sides...)
+
+	// The merge is only recorded once the index holds its result, so that aborting it never has to guess whether the
+	// index was edited afterwards.
+	err = index.CheckoutState(ctx, &merged, repoLoc, 0660)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeParams.IndexID, err = indexID(ctx, filepath.Dir(repoLoc))
+	if err != nil {
+		return nil, err
+	}
+
+	err = MergeStowProgress(ctx, repoLoc, mergeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeParams.Conflicts, nil
+}
+
+// abortMerge forgets about the in-progress merge, and restores the index to the balances from before it started.
+func abortMerge(ctx context.Context, root string, force bool) error {
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	inProg, err := MergeIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if !inProg {
+		return errors.New("no merge is in progress")
+	}
+
+	var mergeParams MergeParameters
+	err = MergeUnstowProgress(ctx, repoLoc, &mergeParams)
+	if err != nil {
+		return err
+	}
+
+	err = restoreHead(ctx, root, operationMerge, mergeParams.IndexID, force)
+	if err != nil {
+		return err
+	}
+
+	return MergeResetProgress(ctx, repoLoc)
+}
+
+// boolCount finds how many of the provided values are true, to help find flags that are mutually exclusive.
+func boolCount(values ...bool) int {
+	var retval int
+	for _, v := range values {
+		if v {
+			retval++
+		}
+	}
+	return retval
+}
+
+// printConflictSummary explains what to do after a merge that has conflicts.
+func printConflictSummary(output io.Writer, conflicts []merge.Conflict) {
+	fmt.Fprintf(output, "Merge has %d conflict(s):\n", len(conflicts))
+	for _, conflict := range conflicts {
+		fmt.Fprintf(output, "\t%s\n", conflict.Path)
+	}
+	fmt.Fprintln(output, "Each holds the common balance plus every branch's change to it. Correct them in the index, mark each with \"merge --resolve {path}\", then commit.")
+}
+
+// printMergeStatus lists the conflicts that remain in the in-progress merge, along with each branch's balance.
+func printMergeStatus(ctx context.Context, output io.Writer, repoLoc string) error {
+	inProg, err := MergeIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if !inProg {
+		return printNotInProgress(ctx, output, repoLoc, operationMerge)
+	}
+
+	var mergeParams MergeParameters
+	err = MergeUnstowProgress(ctx, repoLoc, &mergeParams)
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintln(output, mergeParams.Comment); err != nil {
+		return err
+	}
+
+	if len(mergeParams.Conflicts) == 0 {
+		_, err = fmt.Fprintln(output, "All conflicts are resolved, commit to finish the merge.")
+		return err
+	}
+
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "Unresolved:")
+	for _, conflict := range mergeParams.Conflicts {
+		fmt.Fprintf(writer, "\t%s\n", conflict.Path)
+		fmt.Fprintf(writer, "\t\tbase:\t%s\n", describeConflictBalance(conflict.Base))
+		for i, side := range conflict.Sides {
+			name := fmt.Sprintf("side %d", i+1)
+			if i < len(mergeParams.ParentNames) {
+				name = string(mergeParams.ParentNames[i])
+			}
+			fmt.Fprintf(writer, "\t\t%s:\t%s\n", name, describeConflictBalance(side))
+		}
+		fmt.Fprintf(writer, "\t\tmerged:\t%s\n", describeConflictBalance(conflict.Merged))
+	}
+	return writer.Flush()
+}
+
+func describeConflictBalance(subject envelopes.Balance) string {
+	if subject == nil {
+		return "(absent)"
+	}
+	return subject.String()
+}
+
+// resolveConflicts marks accounts and budgets in the in-progress merge as no longer needing attention.
+func resolveConflicts(ctx context.Context, repoLoc string, paths []string) error {
+	inProg, err := MergeIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if !inProg {
+		return errors.New("no merge is in progress")
+	}
+
+	var mergeParams MergeParameters
+	err = MergeUnstowProgress(ctx, repoLoc, &mergeParams)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range paths {
+		name := strings.Trim(strings.TrimPrefix(filepath.ToSlash(raw), "./"), "/")
+
+		remaining := mergeParams.Conflicts[:0]
+		for _, conflict := range mergeParams.Conflicts {
+			if conflict.Path != name {
+				remaining = append(remaining, conflict)
+			}
+		}
+
+		if len(remaining) == len(mergeParams.Conflicts) {
+			return fmt.Errorf("%q isn't an unresolved conflict", raw)
+		}
+		mergeParams.Conflicts = remaining
+	}
+
+	return MergeStowProgress(ctx, repoLoc, mergeParams)
+}
+
 func MergeIsInProgress(_ context.Context, repoLoc string) (bool, error) {
 	_, err := os.Stat(getMergeParamsLoc(repoLoc))
 	if err == nil {
@@ -166,6 +447,10 @@ func getMergeParamsLoc(repoLoc string) string {
 }
 
 func init() {
+	mergeCmd.Flags().Bool(mergeStatusFlag, mergeStatusDefault, mergeStatusUsage)
+	mergeCmd.Flags().Bool(mergeResolveFlag, mergeResolveDefault, mergeResolveUsage)
+	mergeCmd.Flags().Bool(abortFlag, abortDefault, mergeAbortUsage)
+	mergeCmd.Flags().BoolP(forceFlag, forceShorthand, forceDefault, abortForceUsage)
 	rootCmd.AddCommand(mergeCmd)
 }
 
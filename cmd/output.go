@@ -0,0 +1,71 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+)
+
+const (
+	outputFlag      = "output"
+	outputShorthand = "o"
+	outputDefault   = string(format.OutputText)
+	outputUsage     = `How results should be presented. Supported values are "text", "json", and "yaml". Only log, show, balance, diff, and reports can present anything other than text.`
+)
+
+// structuredOutputAnnotation marks the commands that can present their results as JSON or YAML documents.
+const structuredOutputAnnotation = "baronial/structured-output"
+
+// supportStructuredOutput marks a command as honoring every format that "--output" accepts.
+func supportStructuredOutput(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[structuredOutputAnnotation] = "true"
+}
+
+// checkOutputFormat rejects a request for structured output from a command that can only present text, rather than
+// letting it be silently ignored.
+func checkOutputFormat(cmd *cobra.Command, _ []string) error {
+	if !cmd.Flags().Changed(outputFlag) {
+		return nil
+	}
+
+	requested, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cmd.Annotations[structuredOutputAnnotation]; !ok && requested != format.OutputText {
+		return fmt.Errorf("%q doesn't support --%s %s", cmd.CommandPath(), outputFlag, requested)
+	}
+	return nil
+}
+
+// getOutputFormat finds which format a command's results were requested in.
+func getOutputFormat(cmd *cobra.Command) (format.OutputFormat, error) {
+	raw, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return "", err
+	}
+	return format.ParseOutputFormat(raw)
+}
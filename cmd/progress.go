@@ -0,0 +1,118 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+const (
+	abortFlag    = "abort"
+	abortDefault = false
+
+	abortForceUsage = "Along with --abort, discard edits that were made to the index after the operation started."
+)
+
+const (
+	operationMerge  = "merge"
+	operationRevert = "revert"
+)
+
+// operationInProgress names the operation that has been started, but not yet committed or aborted: "merge",
+// "revert", or an empty string if there isn't one.
+func operationInProgress(ctx context.Context, repoLoc string) (string, error) {
+	if inProg, err := MergeIsInProgress(ctx, repoLoc); err != nil {
+		return "", err
+	} else if inProg {
+		return operationMerge, nil
+	}
+
+	if inProg, err := RevertIsInProgress(ctx, repoLoc); err != nil {
+		return "", err
+	} else if inProg {
+		return operationRevert, nil
+	}
+
+	return "", nil
+}
+
+// restoreHead replaces the index with the balances as of the most recent transaction, to abort an operation. started
+// is the indexID that was recorded once the operation had written to the index. Unless force is true, an index that has
+// been edited since then is left alone, because those edits may have nothing to do with the operation.
+func restoreHead(ctx context.Context, root string, operation string, started envelopes.ID, force bool) error {
+	if !force && !started.Equal(envelopes.ID{}) {
+		current, err := indexID(ctx, root)
+		if err != nil {
+			return err
+		}
+
+		if !current.Equal(started) {
+			return fmt.Errorf("the index has changed since the %s started, use \"--%s\" to discard those changes too", operation, forceFlag)
+		}
+	}
+
+	repo, err := pack.OpenRepositoryWithCache(ctx, filepath.Join(root, index.RepoName), 10000)
+	if err != nil {
+		return err
+	}
+
+	headID, err := persist.Resolve(ctx, repo, persist.MostRecentTransactionAlias)
+	if err != nil {
+		return err
+	}
+
+	head, err := loadStateAt(ctx, repo, headID)
+	if err != nil {
+		return err
+	}
+
+	return index.CheckoutState(ctx, &head, root, 0660)
+}
+
+// indexID identifies the balances that are currently in the index, so that an operation can later tell whether they
+// have been edited since it wrote them.
+func indexID(ctx context.Context, root string) (envelopes.ID, error) {
+	current, err := index.LoadState(ctx, root)
+	if err != nil {
+		return envelopes.ID{}, err
+	}
+	return current.ID(), nil
+}
+
+// printNotInProgress explains that an operation isn't in progress, pointing out the one that is, if any.
+func printNotInProgress(ctx context.Context, output io.Writer, repoLoc string, operation string) error {
+	other, err := operationInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if other == "" {
+		_, err = fmt.Fprintf(output, "No %s is in progress.\n", operation)
+	} else {
+		_, err = fmt.Fprintf(output, "No %s is in progress, but a %s is. See \"%s --status\".\n", operation, other, other)
+	}
+	return err
+}
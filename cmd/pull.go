@@ -0,0 +1,138 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var pullCmd = &cobra.Command{
+	Use:   "pull [remote] [branch]",
+	Short: "Fetches from a remote, then brings its changes into the checked out branch.",
+	Long: `Fetches from a remote, then brings the remote's branch into the one that is
+checked out. When only one side has new transactions, the checked out branch is
+moved forward to match the remote. When both sides have new transactions, a
+merge is started just as if "merge" had been run with the remote's branch; check
+the balances, then commit to finish it.
+
+When no branch is named, the remote's branch with the same name as the one that
+is checked out is used.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		root, repo, headID, _, err := openCleanIndex(ctx, "pulling")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		from, err := getRemote(ctx, repoLoc, args)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		branch, err := getCurrentBranch(ctx, repo)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		remoteBranch := branch
+		if len(args) > 1 {
+			remoteBranch = args[1]
+		}
+
+		updates, err := remote.Fetch(ctx, repo, repoLoc, from)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		for _, update := range updates {
+			fmt.Println(update)
+		}
+
+		theirs, err := remote.ReadTracking(ctx, repoLoc, from.Name, remoteBranch)
+		if err != nil {
+			logrus.Fatal(remote.ErrNoBranch(from.Name + "/" + remoteBranch))
+		}
+
+		upToDate, err := remote.IsAncestor(ctx, repo, theirs, headID)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if upToDate {
+			fmt.Println("Already up to date.")
+			return
+		}
+
+		fastForward, err := remote.IsAncestor(ctx, repo, headID, theirs)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if fastForward {
+			var target envelopes.Transaction
+			err = repo.LoadTransaction(ctx, theirs, &target)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			err = index.CheckoutTransaction(ctx, &target, root, 0660)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			err = repo.WriteBranch(ctx, branch, theirs)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			fmt.Printf("Fast-forwarded %s to %s.\n", branch, theirs)
+			return
+		}
+
+		tracking := remote.TrackingReader{RepositoryReader: repo, RepoLoc: repoLoc}
+		conflicts, err := startMerge(ctx, repoLoc, tracking, []persist.RefSpec{persist.RefSpec(from.Name + "/" + remoteBranch)})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if len(conflicts) > 0 {
+			printConflictSummary(os.Stdout, conflicts)
+			return
+		}
+
+		fmt.Println("Both sides have new transactions, so a merge was started. Please check balances for accuracy, make any necessary reverts, and commit.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+}
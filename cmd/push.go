@@ -0,0 +1,96 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push [remote] [branch]",
+	Short: "Copies a branch's transactions to a remote, and moves the remote's branch to match.",
+	Long: `Copies every transaction in the history of a branch that a remote doesn't have
+yet, then moves the remote's branch of the same name to match. If the remote's
+branch has transactions that aren't present locally, nothing is changed; pull
+them in first, or use --force to discard them from the remote's branch.
+
+When no branch is named, the one that is checked out is pushed.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		to, err := getRemote(ctx, repoLoc, args)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var branch string
+		if len(args) > 1 {
+			branch = args[1]
+		} else {
+			branch, err = getCurrentBranch(ctx, repo)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		force, err := cmd.Flags().GetBool(forceFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		update, err := remote.Push(ctx, repo, repoLoc, to, branch, force)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if update.Old.Equal(update.New) {
+			fmt.Println("Everything up to date.")
+			return
+		}
+		fmt.Println(update)
+
+		// Forcing a push to a copy that has the branch checked out leaves its index showing the balances from before.
+		if dest, err := remote.Open(ctx, to.Location); err == nil {
+			if current, err := dest.Current(ctx); err == nil && string(current) == branch {
+				logrus.Warnf("%s has %q checked out, check it out again there to see the new balances", to.Name, branch)
+			}
+		}
+	},
+}
+
+func init() {
+	pushCmd.Flags().BoolP(forceFlag, forceShorthand, forceDefault, "Move the remote's branch even if it has transactions that aren't present locally, or is checked out there.")
+	rootCmd.AddCommand(pushCmd)
+}
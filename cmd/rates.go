@@ -0,0 +1,264 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/rates"
+	"github.com/marstr/baronial/internal/report"
+)
+
+const (
+	inFlag  = "in"
+	inUsage = "Value every asset in terms of this one, using the rates recorded by the \"rates\" command."
+)
+
+const (
+	ratesInUsage   = "The asset that rates are expressed in."
+	ratesInDefault = string(envelopes.DefaultAsset)
+)
+
+const (
+	ratesDateFlag      = "date"
+	ratesDateShorthand = "t"
+	ratesDateDefault   = "<current date>"
+	ratesDateUsage     = "The day that the rate takes effect."
+)
+
+var ratesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "Manages the exchange rates used to value assets against one another.",
+	Long: `Records what one asset was worth in terms of another, for example a foreign
+currency or shares of a fund in terms of dollars. Each rate takes effect on the
+day it is dated, and stays in effect until a newer one is recorded.
+
+Once rates are recorded, the "balance", "diff", and "report" commands accept
+--in, which values every asset in terms of a single one using the rate in effect
+at the time of each transaction.`,
+}
+
+var ratesSetCmd = &cobra.Command{
+	Use:   "set {asset} {rate}",
+	Short: "Records what one unit of an asset was worth on a given day.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		value, ok := new(big.Rat).SetString(args[1])
+		if !ok || value.Sign() <= 0 {
+			logrus.Fatalf("%q is not a positive rate", args[1])
+		}
+
+		in, err := cmd.Flags().GetString(inFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		rate := rates.Rate{
+			Date:  time.Now(),
+			Asset: envelopes.AssetType(args[0]),
+			In:    envelopes.AssetType(in),
+			Value: value,
+		}
+		if rate.Asset == rate.In {
+			logrus.Fatalf("a rate must convert between two different assets")
+		}
+
+		if cmd.Flags().Changed(ratesDateFlag) {
+			rate.Date, err = getTimeFlag(cmd, ratesDateFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		err = updateRates(ctx, func(store *rates.Store) {
+			store.Add(rate)
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var ratesImportCmd = &cobra.Command{
+	Use:   "import {file}",
+	Short: "Records rates from a CSV file with columns for the date, asset, rate, and optionally the asset it's in.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		in, err := cmd.Flags().GetString(inFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		handle, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		defer handle.Close()
+
+		imported, err := rates.ReadCSV(ctx, handle, envelopes.AssetType(in))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = updateRates(ctx, func(store *rates.Store) {
+			for _, rate := range imported {
+				store.Add(rate)
+			}
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Imported %d rate(s).\n", len(imported))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var ratesListCmd = &cobra.Command{
+	Use:     "list [asset...]",
+	Aliases: []string{"ls"},
+	Short:   "Shows the rates that have been recorded.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		store, err := rates.Load(ctx, filepath.Join(root, index.RepoName))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		wanted := make(map[envelopes.AssetType]struct{}, len(args))
+		for _, arg := range args {
+			wanted[envelopes.AssetType(arg)] = struct{}{}
+		}
+
+		for _, rate := range store.Rates() {
+			if len(wanted) > 0 {
+				_, assetWanted := wanted[rate.Asset]
+				_, inWanted := wanted[rate.In]
+				if !assetWanted && !inWanted {
+					continue
+				}
+			}
+
+			err = writeRate(cmd.OutOrStdout(), rate)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ratesCmd)
+	ratesCmd.AddCommand(ratesSetCmd)
+	ratesCmd.AddCommand(ratesImportCmd)
+	ratesCmd.AddCommand(ratesListCmd)
+
+	ratesSetCmd.Flags().String(inFlag, ratesInDefault, ratesInUsage)
+	ratesSetCmd.Flags().StringP(ratesDateFlag, ratesDateShorthand, ratesDateDefault, ratesDateUsage)
+	ratesImportCmd.Flags().String(inFlag, ratesInDefault, ratesInUsage+" Used for rows that don't name one.")
+}
+
+// updateRates loads the rates recorded in the current repository, lets them be modified, then saves them.
+func updateRates(ctx context.Context, modify func(*rates.Store)) error {
+	root, err := index.RootDirectory(".")
+	if err != nil {
+		return err
+	}
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	store, err := rates.Load(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	modify(store)
+
+	return rates.Write(ctx, repoLoc, store)
+}
+
+func writeRate(output io.Writer, rate rates.Rate) error {
+	value := strings.TrimRight(strings.TrimRight(rate.Value.FloatString(6), "0"), ".")
+	_, err := fmt.Fprintf(output, "%s\t1 %s = %s %s\n", rate.Date.Format("2006-01-02"), rate.Asset, value, rate.In)
+	return err
+}
+
+// getConversion finds which asset, if any, results were requested to be valued in. When one was requested, the
+// rates recorded in the repository are loaded as well; otherwise the returned Store is nil.
+func getConversion(ctx context.Context, cmd *cobra.Command, root string) (*rates.Store, envelopes.AssetType, error) {
+	if !cmd.Flags().Changed(inFlag) {
+		return nil, "", nil
+	}
+
+	in, err := cmd.Flags().GetString(inFlag)
+	if err != nil {
+		return nil, "", err
+	}
+
+	store, err := rates.Load(ctx, filepath.Join(root, index.RepoName))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return store, envelopes.AssetType(in), nil
+}
+
+// getValuation is like getConversion, but packages the result for use in reports. If no conversion was requested,
+// the returned report.Valuation is nil.
+func getValuation(ctx context.Context, cmd *cobra.Command, root string) (report.Valuation, error) {
+	store, in, err := getConversion(ctx, cmd, root)
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	return func(subject envelopes.Balance, when time.Time) (envelopes.Balance, error) {
+		return store.Convert(subject, in, when)
+	}, nil
+}
@@ -0,0 +1,361 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+const (
+	reconcileAsOfFlag      = "as-of"
+	reconcileAsOfShorthand = "a"
+	reconcileAsOfDefault   = "<current date/time>"
+	reconcileAsOfUsage     = "The closing date of the statement. Transactions posted after this are not considered."
+)
+
+const (
+	reconcileMarkFlag      = "mark"
+	reconcileMarkShorthand = "m"
+	reconcileMarkDefault   = false
+	reconcileMarkUsage     = "Record the listed transactions as reconciled, so they aren't shown again."
+)
+
+// ReconcileRecord tracks which transactions have been matched against statements from a financial institution.
+type ReconcileRecord struct {
+	Statements []ReconciledStatement `json:"statements,omitempty"`
+	Reconciled []envelopes.ID        `json:"reconciled,omitempty"`
+}
+
+// ReconciledStatement captures the details of a single bank statement that has been reconciled.
+type ReconciledStatement struct {
+	AsOf         time.Time         `json:"as_of"`
+	Balance      envelopes.Balance `json:"balance"`
+	Difference   envelopes.Balance `json:"difference,omitempty"`
+	Head         envelopes.ID      `json:"head"`
+	ReconciledAt time.Time         `json:"reconciled_at"`
+}
+
+type reconcileEntry struct {
+	ID          envelopes.ID
+	Transaction envelopes.Transaction
+	Impact      envelopes.Balance
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile {account} {statement balance}",
+	Short: "Compares an account's history against the balance on a bank statement.",
+	Long: `Finds the balance that has been recorded for an account as of a statement's
+closing date, and compares it to the balance the financial institution reports.
+Transactions that touched the account and haven't been reconciled yet are listed,
+as they are the ones that can explain any difference.
+
+Once everything matches, run the command again with --mark to remember that the
+listed transactions have been reconciled. Later runs will only show transactions
+that are new since then.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		statementBal, err := envelopes.ParseBalance([]byte(args[1]))
+		if err != nil {
+			logrus.Fatalf("%q not recognized as an amount", args[1])
+		}
+
+		asOf := time.Now()
+		if cmd.Flags().Changed(reconcileAsOfFlag) {
+			var rawAsOf string
+			rawAsOf, err = cmd.Flags().GetString(reconcileAsOfFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			asOf, err = cast.ToTimeE(rawAsOf)
+			if err != nil {
+				logrus.Fatalf("unable to parse time from %q because: %v", rawAsOf, err)
+			}
+			asOf = endOfDay(asOf)
+		}
+
+		mark, err := cmd.Flags().GetBool(reconcileMarkFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if mark {
+			unlock := lockRepository(ctx, cmd, ".")
+			defer unlock()
+		}
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		entity, err := getEntityName(root, args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		dir, account := index.SplitEntityName(entity)
+		if dir != index.AccountsDir || account == "" {
+			logrus.Fatalf("%q does not name an account", args[0])
+		}
+
+		var repo persist.RepositoryReader
+		repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var head envelopes.ID
+		head, err = persist.Resolve(ctx, repo, persist.MostRecentTransactionAlias)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var record ReconcileRecord
+		err = ReconcileUnstow(ctx, repoLoc, account, &record)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Fatal(err)
+		}
+
+		recorded, pending, err := findUnreconciled(ctx, repo, head, account, asOf, record)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		difference := statementBal.Sub(recorded)
+
+		err = writeReconciliation(cmd.OutOrStdout(), statementBal, recorded, difference, pending)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if !mark {
+			return
+		}
+
+		if !difference.Equal(envelopes.Balance{}) {
+			var force bool
+			force, err = cmd.Flags().GetBool(forceFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			if !force {
+				shouldContinue, err := promptToContinue(
+					ctx,
+					"statement and recorded balance differ, mark as reconciled anyway?",
+					cmd.OutOrStdout(),
+					cmd.InOrStdin())
+				if err != nil {
+					logrus.Fatal(err)
+				}
+
+				if !shouldContinue {
+					return
+				}
+			}
+		}
+
+		for _, entry := range pending {
+			record.Reconciled = append(record.Reconciled, entry.ID)
+		}
+		record.Statements = append(record.Statements, ReconciledStatement{
+			AsOf:         asOf,
+			Balance:      statementBal,
+			Difference:   difference,
+			Head:         head,
+			ReconciledAt: time.Now(),
+		})
+
+		err = ReconcileStow(ctx, repoLoc, account, record)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+
+	reconcileCmd.Flags().StringP(reconcileAsOfFlag, reconcileAsOfShorthand, reconcileAsOfDefault, reconcileAsOfUsage)
+	reconcileCmd.Flags().BoolP(reconcileMarkFlag, reconcileMarkShorthand, reconcileMarkDefault, reconcileMarkUsage)
+	reconcileCmd.Flags().BoolP(forceFlag, forceShorthand, forceDefault, "Mark transactions as reconciled without prompting, even if the balances don't match.")
+}
+
+// findUnreconciled totals the changes to an account made by transactions posted no later than asOf, and lists those
+// which haven't been marked as reconciled yet, oldest first.
+func findUnreconciled(
+	ctx context.Context,
+	loader persist.Loader,
+	head envelopes.ID,
+	account string,
+	asOf time.Time,
+	record ReconcileRecord) (envelopes.Balance, []reconcileEntry, error) {
+
+	reconciled := make(map[envelopes.ID]struct{}, len(record.Reconciled))
+	for _, id := range record.Reconciled {
+		reconciled[id] = struct{}{}
+	}
+
+	var recorded envelopes.Balance
+	var pending []reconcileEntry
+	if head.Equal(envelopes.ID{}) {
+		return recorded, pending, nil
+	}
+
+	walker := persist.Walker{Loader: loader}
+	err := walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		if transaction.PostedTime.After(asOf) {
+			return nil
+		}
+
+		impact, err := persist.LoadImpact(ctx, loader, transaction)
+		if err != nil {
+			return err
+		}
+
+		delta, ok := impact.Accounts[account]
+		if !ok || delta.Equal(envelopes.Balance{}) {
+			return nil
+		}
+
+		recorded = recorded.Add(delta)
+		if _, ok := reconciled[id]; !ok {
+			pending = append(pending, reconcileEntry{ID: id, Transaction: transaction, Impact: delta})
+		}
+		return nil
+	}, head)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Transaction.PostedTime.Before(pending[j].Transaction.PostedTime)
+	})
+
+	return recorded, pending, nil
+}
+
+func writeReconciliation(output io.Writer, statementBal, recorded, difference envelopes.Balance, pending []reconcileEntry) (err error) {
+	_, err = fmt.Fprintf(output, "Statement Balance:\t%s\n", statementBal)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(output, "Recorded Balance: \t%s\n", recorded)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(output, "Difference:       \t%s\n", difference)
+	if err != nil {
+		return
+	}
+
+	if len(pending) == 0 {
+		_, err = fmt.Fprintln(output, "No unreconciled transactions.")
+		return
+	}
+
+	_, err = fmt.Fprintln(output, "Unreconciled Transactions:")
+	if err != nil {
+		return
+	}
+	for _, entry := range pending {
+		_, err = fmt.Fprintf(
+			output,
+			"\t%s\t%s\t%s\t%s\n",
+			entry.ID,
+			entry.Transaction.PostedTime.Format("2006-01-02"),
+			entry.Impact,
+			entry.Transaction.Merchant)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// endOfDay moves times that were specified as just a date to the last moment of that date, so that comparisons
+// include everything that happened that day.
+func endOfDay(subject time.Time) time.Time {
+	year, month, day := subject.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, subject.Location())
+	if subject.Equal(midnight) {
+		return midnight.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return subject
+}
+
+// ReconcileStow saves which transactions have been reconciled for an account.
+func ReconcileStow(_ context.Context, repoLoc string, account string, record ReconcileRecord) error {
+	const filePermissions = 0660
+	loc := getReconcileLoc(repoLoc, account)
+
+	toWrite, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal reconciliation record: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(loc), filePermissions|os.ModeDir|0110)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(loc, toWrite, filePermissions)
+	if err != nil {
+		return fmt.Errorf("couldn't write reconciliation record: %w", err)
+	}
+	return nil
+}
+
+// ReconcileUnstow reads which transactions have been reconciled for an account. If the account has never been
+// reconciled, the error satisfies os.IsNotExist.
+func ReconcileUnstow(_ context.Context, repoLoc string, account string, destination *ReconcileRecord) error {
+	contents, err := os.ReadFile(getReconcileLoc(repoLoc, account))
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(contents, destination)
+	if err != nil {
+		return fmt.Errorf("couldn't parse the reconciliation record json: %w", err)
+	}
+	return nil
+}
+
+func getReconcileLoc(repoLoc string, account string) string {
+	return filepath.Join(repoLoc, "reconcile", filepath.FromSlash(account)+".json")
+}
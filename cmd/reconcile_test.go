@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+// newReconcileHistory commits a transaction for each of the provided changes to the "bank/checking" account, posted
+// at the corresponding time, and returns their IDs.
+func newReconcileHistory(ctx context.Context, t *testing.T, repo *filesystem.Repository, posted []time.Time, deltas []int64) []envelopes.ID {
+	var retval []envelopes.ID
+	var parents []envelopes.ID
+	var balance int64
+	for i := range posted {
+		balance += deltas[i]
+		transaction := envelopes.Transaction{
+			State: &envelopes.State{
+				Accounts: envelopes.Accounts{"bank/checking": envelopes.Balance{"USD": big.NewRat(balance, 1)}},
+				Budget:   &envelopes.Budget{Balance: envelopes.Balance{"USD": big.NewRat(balance, 1)}},
+			},
+			PostedTime: posted[i],
+			Amount:     envelopes.Balance{"USD": big.NewRat(deltas[i], 1)},
+			Merchant:   "merchant",
+			Parents:    parents,
+		}
+
+		err := repo.WriteTransaction(ctx, transaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parents = []envelopes.ID{transaction.ID()}
+		retval = append(retval, transaction.ID())
+	}
+	return retval
+}
+
+func TestReconcile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLoc := t.TempDir()
+	repo, err := filesystem.OpenRepository(ctx, repoLoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asOf := endOfDay(time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC))
+	ids := newReconcileHistory(ctx, t, repo, []time.Time{
+		time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		asOf,
+		time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+	}, []int64{100, -20, -5})
+	head := ids[len(ids)-1]
+
+	t.Run("cutoff", func(t *testing.T) {
+		recorded, pending, err := findUnreconciled(ctx, repo, head, "bank/checking", asOf, ReconcileRecord{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := (envelopes.Balance{"USD": big.NewRat(80, 1)}); !recorded.Equal(expected) {
+			t.Logf("\n\tgot:  %s\n\twant: %s", recorded, expected)
+			t.Fail()
+		}
+
+		if len(pending) != 2 || !pending[0].ID.Equal(ids[0]) || !pending[1].ID.Equal(ids[1]) {
+			t.Logf("expected the transactions posted on or before %s, got: %v", asOf, pending)
+			t.Fail()
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		recorded, pending, err := findUnreconciled(ctx, repo, head, "bank/checking", asOf, ReconcileRecord{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statementBal := envelopes.Balance{"USD": big.NewRat(75, 1)}
+		output := &bytes.Buffer{}
+		err = writeReconciliation(output, statementBal, recorded, statementBal.Sub(recorded), pending)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := output.String()
+		for _, expected := range []string{"Difference:       \tUSD -5.000", ids[0].String(), ids[1].String()} {
+			if !strings.Contains(got, expected) {
+				t.Logf("expected report to contain %q, got:\n%s", expected, got)
+				t.Fail()
+			}
+		}
+		if strings.Contains(got, ids[2].String()) {
+			t.Logf("expected report to omit the transaction posted after the statement, got:\n%s", got)
+			t.Fail()
+		}
+	})
+
+	t.Run("mark", func(t *testing.T) {
+		err := ReconcileStow(ctx, repoLoc, "bank/checking", ReconcileRecord{Reconciled: []envelopes.ID{ids[0]}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var record ReconcileRecord
+		err = ReconcileUnstow(ctx, repoLoc, "bank/checking", &record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorded, pending, err := findUnreconciled(ctx, repo, head, "bank/checking", asOf, record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := (envelopes.Balance{"USD": big.NewRat(80, 1)}); !recorded.Equal(expected) {
+			t.Logf("reconciled transactions should still count toward the balance\n\tgot:  %s\n\twant: %s", recorded, expected)
+			t.Fail()
+		}
+
+		if len(pending) != 1 || !pending[0].ID.Equal(ids[1]) {
+			t.Logf("expected only the unmarked transaction to be pending, got: %v", pending)
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/remote"
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Lists the other copies of this repository that transactions can be exchanged with.",
+	Long: `Lists the other copies of this repository that transactions can be exchanged with
+using "fetch", "push", and "pull". A remote's location is the directory holding
+its index, for example a folder on a shared drive.
+
+After a remote's branches have been fetched, they can be referred to by
+prefixing them with the name of the remote, for example "origin/master".`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		remotes, err := remote.Load(ctx, repoLoc)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		output := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, entry := range remotes {
+			_, err = fmt.Fprintf(output, "%s\t%s\n", entry.Name, entry.Location)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		err = output.Flush()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var remoteAddCmd = &cobra.Command{
+	Use:   "add {name} {location}",
+	Short: "Records another copy of this repository that transactions can be exchanged with.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		location := args[1]
+		if abs, err := filepath.Abs(location); err == nil {
+			location = abs
+		}
+
+		if _, err = remote.Open(ctx, location); err != nil {
+			logrus.Warn("the remote can't be reached right now because: ", err)
+		}
+
+		err = remote.Add(ctx, repoLoc, args[0], location)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var remoteRemoveCmd = &cobra.Command{
+	Use:     "remove {name}",
+	Aliases: []string{"rm"},
+	Short:   "Forgets about a remote, and what was known about its branches.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		repoLoc, err := getRepoLoc()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = remote.Remove(ctx, repoLoc, args[0])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+// getRepoLoc finds the metadata directory of the repository that the current working directory belongs to.
+func getRepoLoc() (string, error) {
+	root, err := index.RootDirectory(".")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, index.RepoName), nil
+}
+
+// getRemote finds the remote named by the first argument. When no arguments were provided, the default remote is used,
+// or the only remote if there is exactly one.
+func getRemote(ctx context.Context, repoLoc string, args []string) (remote.Remote, error) {
+	remotes, err := remote.Load(ctx, repoLoc)
+	if err != nil {
+		return remote.Remote{}, err
+	}
+
+	if len(args) > 0 {
+		return remote.Find(remotes, args[0])
+	}
+
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	return remote.Find(remotes, remote.DefaultName)
+}
+
+// getCurrentBranch finds the name of the branch that is checked out, failing if a transaction is checked out instead.
+func getCurrentBranch(ctx context.Context, repo persist.RepositoryReader) (string, error) {
+	current, err := repo.Current(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = repo.ReadBranch(ctx, string(current)); err != nil {
+		return "", fmt.Errorf("%q is checked out, but isn't a branch", current)
+	}
+	return string(current), nil
+}
+
+func init() {
+	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(remoteRemoveCmd)
+	rootCmd.AddCommand(remoteCmd)
+}
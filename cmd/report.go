@@ -0,0 +1,83 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	reportFromFlag    = "from"
+	reportFromDefault = "<start of the current year>"
+	reportFromUsage   = "The earliest date to include in the report."
+)
+
+const (
+	reportToFlag    = "to"
+	reportToDefault = "<current date/time>"
+	reportToUsage   = "The latest date to include in the report."
+)
+
+const (
+	reportByFlag      = "by"
+	reportByShorthand = "b"
+	reportByDefault   = "month"
+	reportByUsage     = `How to group transactions over time. Supported values are "day", "week", "month", and "year".`
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarizes the history of the current branch.",
+	Long: `Reports look at every transaction in the history of the current branch, and
+group what changed by account or budget and by period of time.`,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.PersistentFlags().String(reportFromFlag, reportFromDefault, reportFromUsage)
+	reportCmd.PersistentFlags().String(reportToFlag, reportToDefault, reportToUsage)
+	reportCmd.PersistentFlags().String(inFlag, "", inUsage)
+}
+
+// getReportRange finds the span of time that a report should cover. Dates without a time of day are treated as
+// lasting until the end of that day.
+func getReportRange(cmd *cobra.Command) (from time.Time, to time.Time, err error) {
+	now := time.Now()
+	from = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	to = now
+
+	if cmd.Flags().Changed(reportFromFlag) {
+		from, err = getTimeFlag(cmd, reportFromFlag)
+		if err != nil {
+			return
+		}
+	}
+
+	if cmd.Flags().Changed(reportToFlag) {
+		to, err = getTimeFlag(cmd, reportToFlag)
+		if err != nil {
+			return
+		}
+		to = endOfDay(to)
+	}
+
+	return
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/report"
+)
+
+const (
+	historyByTransaction = "transaction"
+	historyByUsage       = `How often to show balances. Supported values are "transaction", "day", "week", "month", and "year". Periods show the balance as of the last transaction in them.`
+)
+
+const (
+	historyCSVFlag    = "csv"
+	historyCSVDefault = false
+	historyCSVUsage   = "Write comma separated values, with one column per asset type of each account or budget, for use in a spreadsheet."
+)
+
+// HistoryPointDocument is the structured form of a single row of the "report history" command.
+type HistoryPointDocument struct {
+	Time     time.Time                         `json:"time" yaml:"time"`
+	ID       string                            `json:"id" yaml:"id"`
+	Label    string                            `json:"label" yaml:"label"`
+	Balances map[string]format.BalanceDocument `json:"balances" yaml:"balances"`
+}
+
+var reportHistoryCmd = &cobra.Command{
+	Use:   "history [{account | budget}...]",
+	Short: "Shows the balance of accounts or budgets over time.",
+	Long: `Shows the recursive balance of each of the given accounts or budgets, as it was
+recorded by each transaction in the history of the current branch. Names are
+relative to the root of the repository, for example "accounts/checking" or
+"budget/groceries". When no names are given, the total of all accounts is shown.
+
+Like other reports, only the current year is shown unless --from and --to are
+used to choose a different range.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		entities := args
+		if len(entities) == 0 {
+			entities = []string{index.AccountsDir}
+		}
+		for i := range entities {
+			entities[i] = strings.Trim(strings.TrimPrefix(filepath.ToSlash(entities[i]), "./"), "/")
+			if dir, _ := index.SplitEntityName(entities[i]); dir == "" {
+				logrus.Fatalf("%q was recognized as neither a budget nor an account", entities[i])
+			}
+		}
+
+		from, to, err := getReportRange(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		rawPeriod, err := cmd.Flags().GetString(reportByFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		var period report.Period
+		if rawPeriod != historyByTransaction {
+			period, err = report.ParsePeriod(rawPeriod)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		useCSV, err := cmd.Flags().GetBool(historyCSVFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		outputFormat, err := getOutputFormat(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var repo persist.RepositoryReader
+		repo, err = pack.OpenRepositoryWithCache(ctx, filepath.Join(root, index.RepoName), 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		head, err := persist.Resolve(ctx, repo, persist.MostRecentTransactionAlias)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		value, err := getValuation(ctx, cmd, root)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var points []report.Point
+		if !head.Equal(envelopes.ID{}) {
+			points, err = report.History(ctx, repo, head, from, to, period, entities, value)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		switch {
+		case useCSV:
+			err = writeHistoryCSV(cmd.OutOrStdout(), entities, points)
+		case outputFormat != format.OutputText:
+			documents := make([]HistoryPointDocument, 0, len(points))
+			for _, point := range points {
+				doc := HistoryPointDocument{
+					Time:     point.Time,
+					ID:       point.ID.String(),
+					Label:    point.Label,
+					Balances: make(map[string]format.BalanceDocument, len(point.Balances)),
+				}
+				for entity, bal := range point.Balances {
+					doc.Balances[entity] = format.NewBalanceDocument(bal)
+				}
+				documents = append(documents, doc)
+			}
+			err = format.WriteDocument(cmd.OutOrStdout(), outputFormat, documents)
+		default:
+			err = writeHistoryText(cmd.OutOrStdout(), entities, points)
+		}
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportHistoryCmd)
+
+	reportHistoryCmd.Flags().StringP(reportByFlag, reportByShorthand, historyByTransaction, historyByUsage)
+	reportHistoryCmd.Flags().Bool(historyCSVFlag, historyCSVDefault, historyCSVUsage)
+	supportStructuredOutput(reportHistoryCmd)
+}
+
+func writeHistoryText(output io.Writer, entities []string, points []report.Point) error {
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+
+	line := "Date\tTransaction\t" + strings.Join(entities, "\t") + "\t"
+	if _, err := fmt.Fprintln(writer, line); err != nil {
+		return err
+	}
+
+	for _, point := range points {
+		line = point.Label + "\t" + point.ID.String() + "\t"
+		for _, entity := range entities {
+			line += point.Balances[entity].String() + "\t"
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// writeHistoryCSV prints one column for each asset type that each entity ever held, so that every cell is a plain
+// number that a spreadsheet can chart.
+func writeHistoryCSV(output io.Writer, entities []string, points []report.Point) error {
+	assets := make(map[string][]envelopes.AssetType, len(entities))
+	for _, entity := range entities {
+		seen := make(map[envelopes.AssetType]struct{})
+		for _, point := range points {
+			for asset := range point.Balances[entity] {
+				seen[asset] = struct{}{}
+			}
+		}
+
+		for asset := range seen {
+			assets[entity] = append(assets[entity], asset)
+		}
+		sort.Slice(assets[entity], func(i, j int) bool {
+			return assets[entity][i] < assets[entity][j]
+		})
+	}
+
+	writer := csv.NewWriter(output)
+
+	header := []string{"date", "transaction"}
+	for _, entity := range entities {
+		for _, asset := range assets[entity] {
+			header = append(header, fmt.Sprintf("%s (%s)", entity, asset))
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, point := range points {
+		record := []string{point.Label, point.ID.String()}
+		for _, entity := range entities {
+			for _, asset := range assets[entity] {
+				magnitude := "0"
+				if found, ok := point.Balances[entity][asset]; ok {
+					magnitude = found.FloatString(2)
+				}
+				record = append(record, magnitude)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
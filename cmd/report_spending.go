@@ -0,0 +1,123 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+	"github.com/marstr/baronial/internal/report"
+)
+
+var reportSpendingCmd = &cobra.Command{
+	Use:   "spending",
+	Short: "Shows how much was spent from each budget, period by period.",
+	Long: `Totals the money that left each budget during each day, week, month, or year in
+a range of time. Transactions are placed by the date they posted.
+
+Only transactions that changed the balance of an account count as spending, so
+moving money between budgets isn't included. Transactions that have been
+reverted are left out, along with the transactions that reverted them.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		from, to, err := getReportRange(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		rawPeriod, err := cmd.Flags().GetString(reportByFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		period, err := report.ParsePeriod(rawPeriod)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		outputFormat, err := getOutputFormat(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		var repo persist.RepositoryReader
+		repo, err = pack.OpenRepositoryWithCache(ctx, filepath.Join(root, index.RepoName), 10000)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		head, err := persist.Resolve(ctx, repo, persist.MostRecentTransactionAlias)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		value, err := getValuation(ctx, cmd, root)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		spending := report.NewTable()
+		if !head.Equal(envelopes.ID{}) {
+			var byBudget *report.Table
+			byBudget, err = report.Spending(ctx, repo, head, from, to, period, value)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			for _, budget := range byBudget.Rows() {
+				for _, column := range byBudget.Columns() {
+					if amount := byBudget.Get(budget, column); amount != nil {
+						spending.Add(budgetEntityName(budget), column, amount)
+					}
+				}
+			}
+		}
+
+		if outputFormat != format.OutputText {
+			err = format.WriteDocument(cmd.OutOrStdout(), outputFormat, report.NewTableDocument(spending, true))
+		} else if len(spending.Rows()) == 0 {
+			_, err = cmd.OutOrStdout().Write([]byte("No spending found.\n"))
+		} else {
+			err = spending.WriteText(cmd.OutOrStdout(), "Budget", true)
+		}
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportSpendingCmd)
+
+	reportSpendingCmd.Flags().StringP(reportByFlag, reportByShorthand, reportByDefault, reportByUsage)
+	supportStructuredOutput(reportSpendingCmd)
+}
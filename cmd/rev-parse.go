@@ -20,11 +20,11 @@ import (
 	"path"
 
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 var revParseCmd = &cobra.Command{
@@ -41,7 +41,7 @@ var revParseCmd = &cobra.Command{
 		}
 
 		var repo persist.RepositoryReader
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, path.Join(root, index.RepoName), 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, path.Join(root, index.RepoName), 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
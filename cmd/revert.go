@@ -19,14 +19,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -34,8 +36,17 @@ import (
 type RevertParameters struct {
 	Comment string         `json:"comment,omitempty"`
 	Reverts []envelopes.ID `json:"reverts"`
+	IndexID envelopes.ID   `json:"index_id,omitempty"`
 }
 
+const revertAbortUsage = "Stop the in-progress revert, restoring the index to the balances from before it started."
+
+const (
+	revertStatusFlag    = "status"
+	revertStatusDefault = false
+	revertStatusUsage   = "Show which transactions the in-progress revert is undoing."
+)
+
 // revertCmd represents the revert command
 var revertCmd = &cobra.Command{
 	Use:   "revert {ref-spec}",
@@ -47,8 +58,12 @@ know to skip the effects of both.
 It is not advised to revert a revert. Even if this tool handles it well, it
 complicates all future tools and many may not do a good job. If you 
 accidentally reverted a transaction, just commit a new transaction that is
-identical to the original.`,
-	Args: cobra.ExactArgs(1),
+identical to the original.
+
+To give up on a revert before committing it, use "revert --abort". If the index
+has been edited since the revert, aborting discards those edits too, so it is
+refused unless "--force" is also given.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := RootContext(cmd)
 		defer cancel()
@@ -59,68 +74,135 @@ identical to the original.`,
 		}
 
 		repoLoc := filepath.Join(root, index.RepoName)
-		var repo persist.RepositoryReaderWriter
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+
+		abort, err := cmd.Flags().GetBool(abortFlag)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		id, err := persist.Resolve(ctx, repo, persist.RefSpec(args[0]))
+		status, err := cmd.Flags().GetBool(revertStatusFlag)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		var toRevert envelopes.Transaction
-		err = repo.LoadTransaction(ctx, id, &toRevert)
+		if !status {
+			unlock := lockRepository(ctx, cmd, root)
+			defer unlock()
+		}
+
+		switch {
+		case abort && status:
+			logrus.Fatalf("--%s and --%s can't be used together", abortFlag, revertStatusFlag)
+		case abort || status:
+			if len(args) > 0 {
+				logrus.Fatalf("no arguments are accepted along with --%s or --%s", abortFlag, revertStatusFlag)
+			}
+
+			if abort {
+				var force bool
+				force, err = cmd.Flags().GetBool(forceFlag)
+				if err != nil {
+					logrus.Fatal(err)
+				}
+				err = abortRevert(ctx, root, force)
+			} else {
+				err = printRevertStatus(ctx, os.Stdout, repoLoc)
+			}
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			return
+		case len(args) == 0:
+			logrus.Fatal("a transaction to revert is required")
+		}
+
+		if inProg, err := MergeIsInProgress(ctx, repoLoc); err != nil {
+			logrus.Fatal(err)
+		} else if inProg {
+			logrus.Fatal("a merge is in progress, commit or abort it before reverting")
+		}
+		var repo persist.RepositoryReaderWriter
+		repo, err = pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		var delta envelopes.Impact
-		delta, err = persist.LoadImpact(ctx, repo, toRevert)
+		id, err := persist.Resolve(ctx, repo, persist.RefSpec(args[0]))
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		var balances *envelopes.State
-		balances, err = index.LoadState(ctx, root)
+		err = revertTransaction(ctx, root, repo, id)
 		if err != nil {
 			logrus.Fatal(err)
 		}
 
-		updated := envelopes.State(balances.Add(envelopes.State(delta.Negate())))
+		fmt.Printf("Undid the effects of transaction %s. Please check current balances for accuracy, make any necessary edits, then commit.", id)
+	},
+}
 
-		err = index.CheckoutState(ctx, &updated, root, 0660)
+// revertTransaction undoes the effects of a transaction in the index, and records that it is being reverted so that
+// the next commit refers to it. Reverting several transactions before committing undoes all of them at once.
+func revertTransaction(ctx context.Context, root string, loader persist.Loader, id envelopes.ID) error {
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	inProg, err := RevertIsInProgress(ctx, repoLoc)
+	if err != nil {
+		logrus.Warn("couldn't see if previous revert was in progress because: ", err)
+	}
+
+	var revertParams RevertParameters
+
+	if inProg {
+		err = RevertUnstowProgress(ctx, repoLoc, &revertParams)
 		if err != nil {
-			logrus.Fatal(err)
+			return fmt.Errorf("couldn't read the currently in-progress revert because: %w", err)
 		}
+	}
 
-		var inProg bool
-		inProg, err = RevertIsInProgress(ctx, repoLoc)
-		if err != nil {
-			logrus.Warn("couldn't see if previous revert was in progress because: ", err)
+	// Undoing the same transaction twice would take its effects out of the index a second time.
+	for _, pending := range revertParams.Reverts {
+		if pending.Equal(id) {
+			return fmt.Errorf("transaction %s is already being reverted", id)
 		}
+	}
 
-		var revertParams RevertParameters
+	var toRevert envelopes.Transaction
+	err = loader.LoadTransaction(ctx, id, &toRevert)
+	if err != nil {
+		return err
+	}
 
-		if inProg {
-			err = RevertUnstowProgress(ctx, repoLoc, &revertParams)
-			if err != nil {
-				logrus.Fatal("couldn't read the currently in-progress revert because: ", err)
-			}
-		}
+	delta, err := persist.LoadImpact(ctx, loader, toRevert)
+	if err != nil {
+		return err
+	}
 
-		revertParams.Reverts = append(revertParams.Reverts, id)
-		revertParams.Comment = getRevertComment(revertParams.Reverts)
+	balances, err := index.LoadState(ctx, root)
+	if err != nil {
+		return err
+	}
 
-		err = RevertStowProgress(ctx, repoLoc, revertParams)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Unable to stow revert information. Please reset to the last known good state.")
-			logrus.Fatal(err)
-		}
+	updated := envelopes.State(balances.Add(envelopes.State(delta.Negate())))
 
-		fmt.Printf("Undid the effects of transaction %s. Please check current balances for accuracy, make any necessary edits, then commit.", id)
-	},
+	err = index.CheckoutState(ctx, &updated, root, 0660)
+	if err != nil {
+		return err
+	}
+
+	revertParams.Reverts = append(revertParams.Reverts, id)
+	revertParams.Comment = getRevertComment(revertParams.Reverts)
+	revertParams.IndexID, err = indexID(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	err = RevertStowProgress(ctx, repoLoc, revertParams)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to stow revert information. Please reset to the last known good state.")
+		return err
+	}
+	return nil
 }
 
 func RevertIsInProgress(_ context.Context, repoLoc string) (bool, error) {
@@ -157,6 +239,55 @@ func RevertResetProgress(_ context.Context, repoLoc string) error {
 	return os.Remove(getRevertParamsLoc(repoLoc))
 }
 
+// abortRevert forgets about the in-progress revert, and restores the index to the balances of the most recent
+// transaction.
+func abortRevert(ctx context.Context, root string, force bool) error {
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	inProg, err := RevertIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if !inProg {
+		return errors.New("no revert is in progress")
+	}
+
+	var revertParams RevertParameters
+	err = RevertUnstowProgress(ctx, repoLoc, &revertParams)
+	if err != nil {
+		return err
+	}
+
+	err = restoreHead(ctx, root, operationRevert, revertParams.IndexID, force)
+	if err != nil {
+		return err
+	}
+
+	return RevertResetProgress(ctx, repoLoc)
+}
+
+// printRevertStatus lists the transactions that the in-progress revert is undoing.
+func printRevertStatus(ctx context.Context, output io.Writer, repoLoc string) error {
+	inProg, err := RevertIsInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if !inProg {
+		return printNotInProgress(ctx, output, repoLoc, operationRevert)
+	}
+
+	var revertParams RevertParameters
+	err = RevertUnstowProgress(ctx, repoLoc, &revertParams)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(output, revertParams.Comment)
+	return err
+}
+
 func getRevertParamsLoc(repoLoc string) string {
 	return filepath.Join(repoLoc, "revert.json")
 }
@@ -180,6 +311,9 @@ func getRevertComment(reverts []envelopes.ID) string {
 }
 
 func init() {
+	revertCmd.Flags().Bool(abortFlag, abortDefault, revertAbortUsage)
+	revertCmd.Flags().BoolP(forceFlag, forceShorthand, forceDefault, abortForceUsage)
+	revertCmd.Flags().Bool(revertStatusFlag, revertStatusDefault, revertStatusUsage)
 	rootCmd.AddCommand(revertCmd)
 
 	// Here you will define your flags and configuration settings.
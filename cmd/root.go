@@ -51,6 +51,7 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: checkOutputFormat,
 }
 
 var (
@@ -97,6 +98,8 @@ func init() {
 	//rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.baronial.yaml)")
 
 	rootCmd.PersistentFlags().Duration(timeoutFlag, timeoutDefault, timeoutUsage)
+	rootCmd.PersistentFlags().Bool(waitFlag, waitDefault, waitUsage)
+	rootCmd.PersistentFlags().StringP(outputFlag, outputShorthand, outputDefault, outputUsage)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,475 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/schedule"
+)
+
+const (
+	scheduleStartFlag      = "start"
+	scheduleStartShorthand = "s"
+	scheduleStartDefault   = "<current date>"
+	scheduleStartUsage     = "The date of the first transaction this template should create."
+)
+
+const (
+	scheduleEndFlag      = "end"
+	scheduleEndShorthand = "e"
+	scheduleEndDefault   = "<never>"
+	scheduleEndUsage     = "The last date that this template should create a transaction."
+)
+
+const (
+	scheduleImpactFlag      = "impact"
+	scheduleImpactShorthand = "i"
+	scheduleImpactUsage     = "An account or budget and how much it should change, for example \"budget/rent=-1200\". May be repeated."
+)
+
+const (
+	scheduleUntilFlag      = "until"
+	scheduleUntilShorthand = "u"
+	scheduleUntilDefault   = "<current date/time>"
+	scheduleUntilUsage     = "Create the transactions that are due on or before this time."
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:     "schedule",
+	Aliases: []string{"sched"},
+	Short:   "Manages transactions that recur on a regular basis.",
+	Long: `Stores templates for transactions that happen over and over again, like rent,
+paychecks, or subscriptions. Each template has a recurrence, a merchant, and the
+amount each account or budget should change by. Running "baronial schedule run"
+commits every transaction that has come due since the last time it was run.`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add {name} {recurrence}",
+	Short: "Creates or replaces a recurring transaction template.",
+	Long: `Creates or replaces a recurring transaction template.
+
+Recurrences may be written as "daily", "weekly", "biweekly", "monthly",
+"quarterly", "yearly", or in the form "every 3 weeks".
+
+For example, to pay rent on the first of every month:
+
+  baronial schedule add rent monthly --start 2024-01-01 -m "Landlord" \
+    -i accounts/checking=-1200 -i budget/housing/rent=-1200`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		var err error
+		created := schedule.Template{Name: args[0]}
+
+		created.Recurrence, err = schedule.ParseRecurrence(args[1])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		year, month, day := time.Now().Date()
+		created.Start = time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+		if cmd.Flags().Changed(scheduleStartFlag) {
+			created.Start, err = getTimeFlag(cmd, scheduleStartFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		if cmd.Flags().Changed(scheduleEndFlag) {
+			var end time.Time
+			end, err = getTimeFlag(cmd, scheduleEndFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			end = endOfDay(end)
+			created.End = &end
+		}
+
+		created.Merchant, err = cmd.Flags().GetString(merchantFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		created.Comment, err = cmd.Flags().GetString(commentFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if cmd.Flags().Changed(amountFlag) {
+			var rawAmount string
+			rawAmount, err = cmd.Flags().GetString(amountFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			created.Amount, err = envelopes.ParseBalance([]byte(rawAmount))
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		var rawImpacts []string
+		rawImpacts, err = cmd.Flags().GetStringArray(scheduleImpactFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if len(rawImpacts) == 0 {
+			logrus.Fatalf("at least one --%s must be provided", scheduleImpactFlag)
+		}
+
+		created.Impacts = make(map[string]envelopes.Balance, len(rawImpacts))
+		probe := envelopes.State{Budget: &envelopes.Budget{}}
+		for _, rawImpact := range rawImpacts {
+			entity, rawDelta, ok := strings.Cut(rawImpact, "=")
+			if !ok {
+				logrus.Fatalf("%q should be written as {account | budget}={amount}", rawImpact)
+			}
+
+			var delta envelopes.Balance
+			delta, err = envelopes.ParseBalance([]byte(rawDelta))
+			if err != nil {
+				logrus.Fatalf("%q not recognized as an amount", rawDelta)
+			}
+
+			err = index.AdjustState(&probe, entity, delta)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			dir, name := index.SplitEntityName(entity)
+			key := dir
+			if name != "" {
+				key = dir + "/" + name
+			}
+			created.Impacts[key] = created.Impacts[key].Add(delta)
+		}
+
+		if accountsBal, budgetBal := probe.Accounts.Balance(), probe.Budget.RecursiveBalance(); !accountsBal.Equal(budgetBal) {
+			logrus.Warnf(
+				"each transaction will change accounts (%s) and budget (%s) by different amounts.",
+				accountsBal,
+				budgetBal)
+		}
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		templates, err := schedule.Load(ctx, repoLoc)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if i := schedule.Find(templates, created.Name); i >= 0 {
+			templates[i] = created
+		} else {
+			templates = append(templates, created)
+		}
+
+		err = schedule.Write(ctx, repoLoc, templates)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Shows each recurring transaction template.",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		templates, err := schedule.Load(ctx, filepath.Join(root, index.RepoName))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		for i := range templates {
+			err = writeTemplate(cmd.OutOrStdout(), &templates[i])
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:     "remove {name}",
+	Aliases: []string{"rm"},
+	Short:   "Deletes a recurring transaction template.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		repoLoc := filepath.Join(root, index.RepoName)
+
+		templates, err := schedule.Load(ctx, repoLoc)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		i := schedule.Find(templates, args[0])
+		if i < 0 {
+			logrus.Fatalf("no scheduled transaction named %q", args[0])
+		}
+
+		err = schedule.Write(ctx, repoLoc, append(templates[:i], templates[i+1:]...))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Commits each scheduled transaction that has come due.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		var err error
+		until := time.Now()
+		if cmd.Flags().Changed(scheduleUntilFlag) {
+			until, err = getTimeFlag(cmd, scheduleUntilFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			until = endOfDay(until)
+		}
+
+		dryrun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = runSchedule(ctx, cmd, until, dryrun)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd, scheduleListCmd, scheduleRemoveCmd, scheduleRunCmd)
+
+	scheduleAddCmd.Flags().StringP(scheduleStartFlag, scheduleStartShorthand, scheduleStartDefault, scheduleStartUsage)
+	scheduleAddCmd.Flags().StringP(scheduleEndFlag, scheduleEndShorthand, scheduleEndDefault, scheduleEndUsage)
+	scheduleAddCmd.Flags().StringP(merchantFlag, merchantShorthand, merchantDefault, merchantUsage)
+	scheduleAddCmd.Flags().StringP(commentFlag, commentShorthand, commentDefault, commentUsage)
+	scheduleAddCmd.Flags().StringP(amountFlag, amountShorthand, amountDefault, amountUsage)
+	scheduleAddCmd.Flags().StringArrayP(scheduleImpactFlag, scheduleImpactShorthand, nil, scheduleImpactUsage)
+
+	scheduleRunCmd.Flags().StringP(scheduleUntilFlag, scheduleUntilShorthand, scheduleUntilDefault, scheduleUntilUsage)
+	scheduleRunCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, dryrunUsage)
+}
+
+// runSchedule commits each occurrence of a scheduled transaction that is due by a given time, recording the progress
+// of each Template as it goes.
+func runSchedule(ctx context.Context, cmd *cobra.Command, until time.Time, dryrun bool) (err error) {
+	root, repo, _, current, err := openCleanIndex(ctx, "running scheduled transactions")
+	if err != nil {
+		return err
+	}
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	templates, err := schedule.Load(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	batch := &batchCommit{root: root, repo: repo}
+	defer func() {
+		if closeErr := batch.Close(ctx); err == nil {
+			err = closeErr
+		}
+	}()
+
+	due := schedule.DueAll(templates, until)
+	for _, occurrence := range due {
+		next := current.DeepCopy()
+		for entity, delta := range occurrence.Template.Impacts {
+			err = index.AdjustState(&next, entity, delta)
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction := envelopes.Transaction{
+			State:       &next,
+			PostedTime:  occurrence.When,
+			EnteredTime: time.Now(),
+			Amount:      occurrence.Template.Amount,
+			Merchant:    occurrence.Template.Merchant,
+			Comment:     occurrence.Template.Comment,
+		}
+		if transaction.Amount == nil {
+			transaction.Amount = envelopes.CalculateAmount(current, next)
+		}
+
+		if dryrun {
+			err = format.ConcisePrintTransaction(ctx, cmd.OutOrStdout(), transaction)
+			if err != nil {
+				return err
+			}
+			current = next
+			continue
+		}
+
+		// The occurrence is recorded as committed before it actually is, so that being interrupted in between can
+		// at worst skip an occurrence, rather than commit it a second time on the next run.
+		occurrence.Template.Committed = occurrence.Index + 1
+		err = schedule.Write(ctx, repoLoc, templates)
+		if err != nil {
+			return err
+		}
+
+		err = batch.Commit(ctx, transaction)
+		if err != nil {
+			occurrence.Template.Committed = occurrence.Index
+			if rollbackErr := schedule.Write(ctx, repoLoc, templates); rollbackErr != nil {
+				return fmt.Errorf("%w (and couldn't mark %q as still due: %v)", err, occurrence.Template.Name, rollbackErr)
+			}
+			return err
+		}
+		current = next
+	}
+
+	if dryrun {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Dry run: %d scheduled transaction(s) would have been committed.\n", len(due))
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Committed %d scheduled transaction(s).\n", len(due))
+	return err
+}
+
+// getTimeFlag parses the value of a flag as a time, in the same formats that the "commit" command accepts.
+func getTimeFlag(cmd *cobra.Command, flag string) (time.Time, error) {
+	raw, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	parsed, err := cast.ToTimeE(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse time from %q because: %v", raw, err)
+	}
+	return parsed, nil
+}
+
+func writeTemplate(output io.Writer, template *schedule.Template) (err error) {
+	_, err = fmt.Fprintln(output, template.Name)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(output, "\tRecurrence:\t%s\n", template.Recurrence)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(output, "\tStart:     \t%s\n", template.Start.Format("2006-01-02"))
+	if err != nil {
+		return
+	}
+	if template.End != nil {
+		_, err = fmt.Fprintf(output, "\tEnd:       \t%s\n", template.End.Format("2006-01-02"))
+		if err != nil {
+			return
+		}
+	}
+	if next, ok := template.Next(); ok {
+		_, err = fmt.Fprintf(output, "\tNext:      \t%s\n", next.Format("2006-01-02"))
+		if err != nil {
+			return
+		}
+	}
+	_, err = fmt.Fprintf(output, "\tMerchant:  \t%s\n", template.Merchant)
+	if err != nil {
+		return
+	}
+	if template.Amount != nil {
+		_, err = fmt.Fprintf(output, "\tAmount:    \t%s\n", template.Amount)
+		if err != nil {
+			return
+		}
+	}
+	if template.Comment != "" {
+		_, err = fmt.Fprintf(output, "\tComment:   \t%s\n", template.Comment)
+		if err != nil {
+			return
+		}
+	}
+
+	entities := make([]string, 0, len(template.Impacts))
+	for entity := range template.Impacts {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	_, err = fmt.Fprintln(output, "\tImpacts:")
+	if err != nil {
+		return
+	}
+	for _, entity := range entities {
+		_, err = fmt.Fprintf(output, "\t\t%s: %s\n", entity, template.Impacts[entity])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
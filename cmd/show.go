@@ -21,12 +21,12 @@ import (
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/envelopes/persist"
-	"github.com/marstr/envelopes/persist/filesystem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/marstr/baronial/internal/format"
 	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
 )
 
 var showCmd = &cobra.Command{
@@ -47,7 +47,7 @@ for the sake of brevity. This command shows all known details of a transaction.`
 		root = path.Join(root, index.RepoName)
 
 		var repo persist.RepositoryReader
-		repo, err = filesystem.OpenRepositoryWithCache(ctx, root, 10000)
+		repo, err = pack.OpenRepositoryWithCache(ctx, root, 10000)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -65,7 +65,27 @@ for the sake of brevity. This command shows all known details of a transaction.`
 			logrus.Fatal(err)
 		}
 
-		err = format.PrettyPrintTransaction(ctx, os.Stdout, repo, target)
+		var outputFormat format.OutputFormat
+		outputFormat, err = getOutputFormat(cmd)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if outputFormat == format.OutputText {
+			err = format.PrettyPrintTransaction(ctx, os.Stdout, repo, target)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			return
+		}
+
+		var document format.TransactionDocument
+		document, err = format.NewDetailedTransactionDocument(ctx, repo, target)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = format.WriteDocument(os.Stdout, outputFormat, document)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -74,4 +94,6 @@ for the sake of brevity. This command shows all known details of a transaction.`
 
 func init() {
 	rootCmd.AddCommand(showCmd)
+
+	supportStructuredOutput(showCmd)
 }
@@ -0,0 +1,241 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/allocate"
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+)
+
+const (
+	spendSplitFlag      = "split"
+	spendSplitShorthand = "s"
+	spendSplitUsage     = "A budget to pay from, and how much of the amount it pays: a fixed amount, a percentage, or \"rest\". For example \"budget/groceries=40\". May be repeated."
+)
+
+var spendCmd = &cobra.Command{
+	Use:   "spend {amount} {account}",
+	Short: "Debits an account and one or more budgets, then commits the transaction.",
+	Long: `Records a purchase in one step: the account is debited the full amount, each
+budget named by --split is debited its share, and the result is committed. The
+index must not have any uncommitted changes.
+
+Each split names a budget, followed by how much of the amount it pays: a fixed
+amount, a percentage of the full amount, or "rest". Fixed amounts are set aside
+first, then percentages, and whatever is left is paid by the budget marked
+"rest". Without a "rest", the splits must add up to the full amount.
+
+    baronial spend 100 accounts/checking --split budget/groceries=40 \
+        --split budget/household=rest -m Costco
+
+A budget named without an amount pays the rest.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		unlock := lockRepository(ctx, cmd, ".")
+		defer unlock()
+
+		amount, err := envelopes.ParseBalance([]byte(args[0]))
+		if err != nil {
+			logrus.Fatalf("%q not recognized as an amount", args[0])
+		}
+
+		rawSplits, err := cmd.Flags().GetStringArray(spendSplitFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if len(rawSplits) == 0 {
+			logrus.Fatalf("at least one --%s is required", spendSplitFlag)
+		}
+
+		root, repo, _, current, err := openCleanIndex(ctx, "spending")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		account, err := getEntityName(root, args[1])
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if dir, name := index.SplitEntityName(account); dir != index.AccountsDir || name == "" {
+			logrus.Fatalf("%q is not an account", args[1])
+		}
+
+		rules := make([]allocate.Rule, 0, len(rawSplits))
+		remainders := 0
+		for _, raw := range rawSplits {
+			target, spec, found := strings.Cut(raw, "=")
+			if !found {
+				spec = "rest"
+			}
+
+			var rule allocate.Rule
+			rule, err = allocate.ParseRule(target, spec)
+			if err != nil {
+				logrus.Fatalf("couldn't understand --%s %q: %v", spendSplitFlag, raw, err)
+			}
+
+			rule.Target, err = getEntityName(root, rule.Target)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if dir, _ := index.SplitEntityName(rule.Target); dir != index.BudgetDir {
+				logrus.Fatalf("%q is not a budget", target)
+			}
+
+			if rule.Kind == allocate.Remainder {
+				remainders++
+			}
+			rules = append(rules, rule)
+		}
+		if remainders > 1 {
+			logrus.Fatal("only one split may pay the rest")
+		}
+
+		allocations, err := allocate.Split(rules, amount)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		next := current.DeepCopy()
+		err = index.AdjustState(&next, account, amount.Negate())
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		for _, allocation := range allocations {
+			err = index.AdjustState(&next, allocation.Target, allocation.Amount.Negate())
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		transaction := envelopes.Transaction{
+			State:       &next,
+			PostedTime:  time.Now(),
+			EnteredTime: time.Now(),
+			Amount:      envelopes.CalculateAmount(current, next),
+		}
+
+		if cmd.Flags().Changed(postedTimeFlag) {
+			transaction.PostedTime, err = getTimeFlag(cmd, postedTimeFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		if cmd.Flags().Changed(actualTimeFlag) {
+			transaction.ActualTime, err = getTimeFlag(cmd, actualTimeFlag)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+
+		transaction.Merchant, err = cmd.Flags().GetString(merchantFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		transaction.Comment, err = cmd.Flags().GetString(commentFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		dryrun, err := cmd.Flags().GetBool(dryrunFlag)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		if dryrun {
+			err = format.ConcisePrintTransaction(ctx, cmd.OutOrStdout(), transaction)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			return
+		}
+
+		err = persist.Commit(ctx, repo, transaction)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = index.CheckoutState(ctx, &next, root, 0660)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		for _, allocation := range allocations {
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", allocation.Target, allocation.Amount)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+// getEntityName finds the name of an existing account or budget directory relative to the root of the index, for
+// example "accounts/checking" or "budget/groceries". Relative paths are interpreted from the current working directory.
+func getEntityName(root string, location string) (string, error) {
+	abs, err := filepath.Abs(location)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(abs)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%q doesn't exist", location)
+	} else if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", location)
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+
+	if dir, _ := index.SplitEntityName(rel); dir == "" {
+		return "", fmt.Errorf("%q was recognized as neither a budget nor an account", location)
+	}
+	return rel, nil
+}
+
+func init() {
+	spendCmd.Flags().StringArrayP(spendSplitFlag, spendSplitShorthand, nil, spendSplitUsage)
+	spendCmd.Flags().StringP(merchantFlag, merchantShorthand, merchantDefault, merchantUsage)
+	spendCmd.Flags().StringP(commentFlag, commentShorthand, commentDefault, commentUsage)
+	spendCmd.Flags().StringP(postedTimeFlag, postedTimeShorthand, postedTimeDefault, postedTimeUsage)
+	spendCmd.Flags().StringP(actualTimeFlag, actualTimeShorthand, actualTimeDefault, actualTimeUsage)
+	spendCmd.Flags().BoolP(dryrunFlag, dryrunShorthand, dryrunDefault, dryrunUsage)
+	rootCmd.AddCommand(spendCmd)
+}
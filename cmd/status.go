@@ -0,0 +1,192 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/marstr/envelopes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/marstr/baronial/internal/format"
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"st"},
+	Short:   "Summarizes what's checked out, and what has changed in the index since.",
+	Long: `Shows the branch that is checked out, any merge or revert that is in progress,
+every account and budget whose balance in the index differs from the most recent
+transaction, and whether the accounts and budgets balance with one another.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := RootContext(cmd)
+		defer cancel()
+
+		root, err := index.RootDirectory(".")
+		if err != nil {
+			logrus.Fatal(err)
+		}
+
+		err = printStatus(ctx, cmd.OutOrStdout(), root)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func printStatus(ctx context.Context, output io.Writer, root string) error {
+	repoLoc := filepath.Join(root, index.RepoName)
+
+	repo, err := pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+	if err != nil {
+		return err
+	}
+
+	current, err := repo.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = repo.ReadBranch(ctx, string(current)); err == nil {
+		fmt.Fprintf(output, "On branch %s\n", current)
+	} else {
+		fmt.Fprintf(output, "Not on a branch, %s is checked out\n", current)
+	}
+
+	operation, err := operationInProgress(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	switch operation {
+	case operationMerge:
+		var mergeParams MergeParameters
+		err = MergeUnstowProgress(ctx, repoLoc, &mergeParams)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(output, "Merge in progress: %s\n", mergeParams.Comment)
+		if count := len(mergeParams.Conflicts); count > 0 {
+			fmt.Fprintf(output, "\t%d unresolved conflict(s), see \"merge --status\"\n", count)
+		}
+	case operationRevert:
+		var revertParams RevertParameters
+		err = RevertUnstowProgress(ctx, repoLoc, &revertParams)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(output, "Revert in progress: %s\n", revertParams.Comment)
+	}
+
+	indexState, head, _, _, err := getDiffStates(ctx, []string{}, root)
+	if err != nil {
+		return err
+	}
+
+	changes := listChanges(*indexState, *head)
+	if len(changes) == 0 {
+		fmt.Fprintln(output, "No changes since the most recent transaction.")
+	} else {
+		fmt.Fprintln(output, "Changes since the most recent transaction:")
+		writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+		for _, change := range changes {
+			fmt.Fprintf(writer, "\t%s\t%s\t%s\n", change.name, change.delta, change.note)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	accountsBal, budgetBal := totalBalances(*indexState)
+	if accountsBal.Equal(budgetBal) {
+		_, err = fmt.Fprintln(output, "Accounts and budgets balance.")
+	} else {
+		_, err = fmt.Fprintf(output, "Accounts (%s) and budgets (%s) are not equal by %s.\n", accountsBal, budgetBal, accountsBal.Sub(budgetBal))
+	}
+	return err
+}
+
+type change struct {
+	name  string
+	delta envelopes.Balance
+	note  string
+}
+
+// listChanges names each account and budget whose balance differs between two States, noting those that only exist in
+// one of them, sorted by name.
+func listChanges(updated, original envelopes.State) []change {
+	diff := updated.Subtract(original)
+
+	var retval []change
+	for name, delta := range diff.Accounts {
+		_, existed := original.Accounts[name]
+		_, exists := updated.Accounts[name]
+		retval = append(retval, change{name: path.Join(index.AccountsDir, name), delta: delta, note: changeNote(existed, exists)})
+	}
+
+	for name, delta := range format.FlattenBudgets(diff) {
+		existed := original.Budget != nil && findBudget(original.Budget, name) != nil
+		exists := updated.Budget != nil && findBudget(updated.Budget, name) != nil
+		retval = append(retval, change{name: path.Join(index.BudgetDir, name), delta: delta, note: changeNote(existed, exists)})
+	}
+
+	sort.Slice(retval, func(i, j int) bool {
+		return retval[i].name < retval[j].name
+	})
+	return retval
+}
+
+// changeNote points out an account or budget that was added or removed, rather than having its balance changed.
+func changeNote(existed, exists bool) string {
+	switch {
+	case !existed:
+		return "(new)"
+	case !exists:
+		return "(removed)"
+	default:
+		return ""
+	}
+}
+
+// totalBalances sums every account, and every budget, in a State. When nothing is amiss, the two are equal.
+func totalBalances(state envelopes.State) (accounts, budget envelopes.Balance) {
+	for _, entry := range state.Accounts {
+		accounts = accounts.Add(entry)
+	}
+
+	if state.Budget != nil {
+		budget = state.Budget.RecursiveBalance()
+	}
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
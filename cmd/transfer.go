@@ -16,21 +16,23 @@
 package cmd
 
 import (
-	"github.com/marstr/baronial/internal/index"
 	"github.com/marstr/envelopes"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var transferCmd = &cobra.Command{
-	Use:     "transfer {amount} {src} {dest}",
+	Use:     "transfer {amount} {src budget | account} {dest budget | account}",
 	Aliases: []string{"t", "tran"},
-	Short:   "Moves funds from one category of spending to another.",
+	Short:   "Moves funds from one category of spending, or account, to another.",
 	Args:    cobra.ExactArgs(3),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := RootContext(cmd)
 		defer cancel()
 
+		unlock := lockRepository(ctx, cmd, args[1])
+		defer unlock()
+
 		rawSrc := args[1]
 		rawDest := args[2]
 		rawMagnitude := args[0]
@@ -39,27 +41,32 @@ var transferCmd = &cobra.Command{
 			logrus.Fatal(err)
 		}
 
-		src, err := index.LoadBudget(ctx, rawSrc)
+		srcIsAccount, err := isAccount(rawSrc)
 		if err != nil {
-			logrus.Fatal(err)
+			logrus.Fatalf("%q was recognized as neither a budget nor an account", rawSrc)
 		}
 
-		dest, err := index.LoadBudget(ctx, rawDest)
+		destIsAccount, err := isAccount(rawDest)
 		if err != nil {
-			logrus.Fatal(err)
+			logrus.Fatalf("%q was recognized as neither a budget nor an account", rawDest)
 		}
 
-		src.Balance = src.Balance.Sub(magnitude)
-		dest.Balance = dest.Balance.Add(magnitude)
+		if srcIsAccount != destIsAccount {
+			logrus.Warn("moving funds between an account and a budget leaves them out of balance, consider using credit or debit instead")
+		}
 
-		err = index.WriteBudget(ctx, rawSrc, *src)
+		err = adjustBalance(ctx, rawSrc, magnitude.Negate())
 		if err != nil {
-			logrus.Error(err)
+			logrus.Fatal(err)
 		}
 
-		err = index.WriteBudget(ctx, rawDest, *dest)
+		err = adjustBalance(ctx, rawDest, magnitude)
 		if err != nil {
-			logrus.Error(err)
+			// Put the funds back, so that they don't disappear from the source without arriving anywhere.
+			if rollbackErr := adjustBalance(ctx, rawSrc, magnitude); rollbackErr != nil {
+				logrus.Fatalf("%v (and %s could not be restored, so its balance is off by %s: %v)", err, rawSrc, magnitude, rollbackErr)
+			}
+			logrus.Fatal(err)
 		}
 	},
 }
@@ -0,0 +1,255 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package allocate divides incoming money between budgets according to a list of rules, so that a paycheck can be
+// spread across a whole budget at once.
+package allocate
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/marstr/envelopes"
+)
+
+// Kind identifies how a Rule decides how much money it receives.
+type Kind int
+
+// These are the kinds of Rule that can be written in a rules file. They are listed in the order that they are applied.
+const (
+	// Fixed rules receive a set amount.
+	Fixed Kind = iota
+
+	// Percent rules receive a share of the full amount being allocated.
+	Percent
+
+	// Remainder rules receive whatever is left once all other rules have been satisfied.
+	Remainder
+)
+
+// Rule describes how much of an amount a single budget should receive.
+type Rule struct {
+	Target  string
+	Kind    Kind
+	Amount  envelopes.Balance
+	Percent *big.Rat
+}
+
+// Allocation is the amount of money that has been set aside for a single target.
+type Allocation struct {
+	Target string
+	Amount envelopes.Balance
+}
+
+// ErrBadRule is returned when a line of a rules file can't be understood.
+type ErrBadRule struct {
+	Line int
+	Text string
+}
+
+func (e ErrBadRule) Error() string {
+	return fmt.Sprintf("line %d: %q is not a recognized rule (expected \"{budget} {amount}\", \"{budget} {n}%%\", or \"{budget} rest\")", e.Line, e.Text)
+}
+
+// ErrOverAllocated is returned when the fixed amounts and percentages of a set of rules add up to more than the amount
+// being allocated.
+type ErrOverAllocated envelopes.Balance
+
+func (e ErrOverAllocated) Error() string {
+	return fmt.Sprintf("rules allocate %s more than is available", envelopes.Balance(e))
+}
+
+// ErrUnderAllocated is returned when a set of rules without a remainder doesn't account for all of the amount being
+// allocated.
+type ErrUnderAllocated envelopes.Balance
+
+func (e ErrUnderAllocated) Error() string {
+	return fmt.Sprintf("rules leave %s unallocated, and don't name a budget to receive the rest", envelopes.Balance(e))
+}
+
+// ErrBadAmount is returned when how much a rule should receive can't be understood.
+type ErrBadAmount string
+
+func (e ErrBadAmount) Error() string {
+	return fmt.Sprintf("%q is not a recognized amount (expected an amount, a percentage like \"20%%\", or \"rest\")", string(e))
+}
+
+var (
+	// ErrNoRemainder is returned when a set of rules doesn't say where leftover money should go.
+	ErrNoRemainder = errors.New("rules must name exactly one budget to receive the remainder")
+)
+
+// ReadRules parses a rules file. Each line names a budget, followed by how much it should receive: a fixed amount
+// like "USD 500", a percentage like "20%", or "rest". Blank lines and anything following a "#" are ignored.
+//
+//	budget/rent       USD 500
+//	budget/savings    20%
+//	budget/groceries  rest
+func ReadRules(ctx context.Context, input io.Reader) ([]Rule, error) {
+	var retval []Rule
+	var remainders int
+
+	scanner := bufio.NewScanner(input)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		line := scanner.Text()
+		if commentStart := strings.Index(line, "#"); commentStart >= 0 {
+			line = line[:commentStart]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, ErrBadRule{Line: lineNumber, Text: line}
+		}
+
+		rule, err := ParseRule(fields[0], strings.TrimPrefix(line, fields[0]))
+		if err != nil {
+			return nil, ErrBadRule{Line: lineNumber, Text: line}
+		}
+
+		if rule.Kind == Remainder {
+			remainders++
+		}
+		retval = append(retval, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if remainders != 1 {
+		return nil, ErrNoRemainder
+	}
+
+	return retval, nil
+}
+
+// ParseRule interprets how much a target should receive: a fixed amount like "USD 500", a percentage like "20%", or
+// "rest".
+func ParseRule(target string, spec string) (Rule, error) {
+	rule := Rule{Target: strings.Trim(target, "/")}
+	spec = strings.TrimSpace(spec)
+
+	switch lowered := strings.ToLower(spec); {
+	case lowered == "rest" || lowered == "remainder":
+		rule.Kind = Remainder
+	case strings.HasSuffix(spec, "%"):
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(strings.TrimSuffix(spec, "%")))
+		if !ok || percent.Sign() < 0 {
+			return Rule{}, ErrBadAmount(spec)
+		}
+		rule.Kind = Percent
+		rule.Percent = percent
+	default:
+		amount, err := envelopes.ParseBalance([]byte(spec))
+		if err != nil {
+			return Rule{}, ErrBadAmount(spec)
+		}
+		rule.Kind = Fixed
+		rule.Amount = amount
+	}
+
+	return rule, nil
+}
+
+// Split divides an amount between the targets of a set of rules. Fixed amounts are set aside first, then
+// percentages of the full amount, rounded down to the nearest hundredth, and finally everything left over goes to
+// the remainder. If no rule receives the remainder, there must not be anything left over. Targets are returned in
+// the order they first appear in the rules, and a target named by more than one rule receives the sum of them.
+func Split(rules []Rule, amount envelopes.Balance) ([]Allocation, error) {
+	retval := make([]Allocation, 0, len(rules))
+	positions := make(map[string]int, len(rules))
+
+	give := func(target string, delta envelopes.Balance) {
+		if i, ok := positions[target]; ok {
+			retval[i].Amount = retval[i].Amount.Add(delta)
+			return
+		}
+		positions[target] = len(retval)
+		retval = append(retval, Allocation{Target: target, Amount: delta})
+	}
+
+	// Reserve each target's place in line, so that results are ordered the same way as the rules.
+	for _, rule := range rules {
+		give(rule.Target, envelopes.Balance{})
+	}
+
+	remaining := amount
+	for _, kind := range []Kind{Fixed, Percent} {
+		for _, rule := range rules {
+			if rule.Kind != kind {
+				continue
+			}
+
+			var delta envelopes.Balance
+			if kind == Fixed {
+				delta = rule.Amount
+			} else {
+				delta = percentOf(amount, rule.Percent)
+			}
+
+			give(rule.Target, delta)
+			remaining = remaining.Sub(delta)
+		}
+	}
+
+	overage := make(envelopes.Balance)
+	for asset, magnitude := range remaining {
+		if magnitude.Sign() < 0 {
+			overage[asset] = new(big.Rat).Neg(magnitude)
+		}
+	}
+	if len(overage) > 0 {
+		return nil, ErrOverAllocated(overage)
+	}
+
+	for _, rule := range rules {
+		if rule.Kind == Remainder {
+			give(rule.Target, remaining)
+			return retval, nil
+		}
+	}
+
+	if !remaining.Equal(envelopes.Balance{}) {
+		return nil, ErrUnderAllocated(remaining)
+	}
+	return retval, nil
+}
+
+func percentOf(amount envelopes.Balance, percent *big.Rat) envelopes.Balance {
+	retval := make(envelopes.Balance, len(amount))
+	for asset, magnitude := range amount {
+		scaled := new(big.Rat).Mul(magnitude, percent)
+		cents := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+		retval[asset] = new(big.Rat).SetFrac(cents, big.NewInt(100))
+	}
+	return retval
+}
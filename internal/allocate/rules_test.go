@@ -0,0 +1,158 @@
+package allocate
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestReadRules(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const rules = `# Paycheck
+budget/groceries  rest
+budget/savings    20%   # for a rainy day
+budget/rent       USD 500
+
+budget/fun        2.5%
+`
+
+	got, err := ReadRules(ctx, strings.NewReader(rules))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []Rule{
+		{Target: "budget/groceries", Kind: Remainder},
+		{Target: "budget/savings", Kind: Percent, Percent: big.NewRat(20, 1)},
+		{Target: "budget/rent", Kind: Fixed, Amount: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+		{Target: "budget/fun", Kind: Percent, Percent: big.NewRat(5, 2)},
+	}
+
+	if len(got) != len(expected) {
+		t.Logf("got %d rules, want %d", len(got), len(expected))
+		t.FailNow()
+	}
+
+	for i := range got {
+		same := got[i].Target == expected[i].Target && got[i].Kind == expected[i].Kind
+		switch expected[i].Kind {
+		case Fixed:
+			same = same && got[i].Amount.Equal(expected[i].Amount)
+		case Percent:
+			same = same && got[i].Percent.Cmp(expected[i].Percent) == 0
+		}
+
+		if !same {
+			t.Logf("rule %d\n\tgot:  %+v\n\twant: %+v", i, got[i], expected[i])
+			t.Fail()
+		}
+	}
+
+	for _, bad := range []string{"budget/a USD 5\n", "budget/a rest\nbudget/b rest\n", "budget/a\nbudget/b rest\n", "budget/a lots\nbudget/b rest\n"} {
+		if _, err := ReadRules(ctx, strings.NewReader(bad)); err == nil {
+			t.Logf("expected an error for %q", bad)
+			t.Fail()
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	rules := []Rule{
+		{Target: "budget/groceries", Kind: Remainder},
+		{Target: "budget/savings", Kind: Percent, Percent: big.NewRat(20, 1)},
+		{Target: "budget/rent", Kind: Fixed, Amount: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+		{Target: "budget/fun", Kind: Percent, Percent: big.NewRat(1, 3)},
+	}
+
+	got, err := Split(rules, envelopes.Balance{"USD": big.NewRat(2000, 1)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []Allocation{
+		{Target: "budget/groceries", Amount: envelopes.Balance{"USD": big.NewRat(109334, 100)}},
+		{Target: "budget/savings", Amount: envelopes.Balance{"USD": big.NewRat(400, 1)}},
+		{Target: "budget/rent", Amount: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+		{Target: "budget/fun", Amount: envelopes.Balance{"USD": big.NewRat(666, 100)}},
+	}
+
+	if len(got) != len(expected) {
+		t.Logf("got %d allocations, want %d", len(got), len(expected))
+		t.FailNow()
+	}
+
+	for i := range got {
+		if got[i].Target != expected[i].Target || !got[i].Amount.Equal(expected[i].Amount) {
+			t.Logf("allocation %d\n\tgot:  %s %s\n\twant: %s %s", i, got[i].Target, got[i].Amount, expected[i].Target, expected[i].Amount)
+			t.Fail()
+		}
+	}
+
+	_, err = Split(rules, envelopes.Balance{"USD": big.NewRat(600, 1)})
+	if _, ok := err.(ErrOverAllocated); !ok {
+		t.Logf("expected over-allocation to be reported, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestSplit_withoutRemainder(t *testing.T) {
+	rules := []Rule{
+		{Target: "budget/groceries", Kind: Fixed, Amount: envelopes.Balance{"USD": big.NewRat(40, 1)}},
+		{Target: "budget/household", Kind: Percent, Percent: big.NewRat(60, 1)},
+	}
+
+	got, err := Split(rules, envelopes.Balance{"USD": big.NewRat(100, 1)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(got) != 2 || !got[1].Amount.Equal(envelopes.Balance{"USD": big.NewRat(60, 1)}) {
+		t.Logf("unexpected allocations: %v", got)
+		t.Fail()
+	}
+
+	_, err = Split(rules, envelopes.Balance{"USD": big.NewRat(110, 1)})
+	if _, ok := err.(ErrUnderAllocated); !ok {
+		t.Logf("expected under-allocation to be reported, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected Kind
+	}{
+		{"40", Fixed},
+		{" USD 12.50", Fixed},
+		{"25%", Percent},
+		{"Rest", Remainder},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseRule("budget/groceries/", tc.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got.Kind != tc.expected || got.Target != "budget/groceries" {
+			t.Logf("%q\n\tgot:  %+v\n\twant kind: %v", tc.spec, got, tc.expected)
+			t.Fail()
+		}
+	}
+
+	if _, err := ParseRule("budget/groceries", "lots"); err == nil {
+		t.Log("expected an error for an amount that isn't a number")
+		t.Fail()
+	}
+}
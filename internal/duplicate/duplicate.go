@@ -0,0 +1,188 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package duplicate looks through history for transactions that were recorded more than once, either because they
+// share an ID assigned by a financial institution, or because they look alike.
+package duplicate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+)
+
+// Reason explains why a Group of transactions are thought to be duplicates of one another.
+type Reason int
+
+const (
+	// ReasonRecordID is for transactions that have the same envelopes.BankRecordID.
+	ReasonRecordID Reason = iota
+
+	// ReasonDetails is for transactions with the same amount and merchant, that happened close together.
+	ReasonDetails
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonRecordID:
+		return "same bank record ID"
+	case ReasonDetails:
+		return "same amount and merchant"
+	default:
+		return "unknown"
+	}
+}
+
+// Entry is a transaction that was found to be part of a Group.
+type Entry struct {
+	ID          envelopes.ID
+	Transaction envelopes.Transaction
+}
+
+// Group is a set of transactions that seem to record the same transfer of funds.
+type Group struct {
+	Reason Reason
+
+	// Entries are sorted by when they were entered into the repository. The first is presumed to be the original.
+	Entries []Entry
+}
+
+// Extras lists the entries that were recorded after the original.
+func (g Group) Extras() []Entry {
+	return g.Entries[1:]
+}
+
+// Find walks the history leading to the heads, and groups the transactions that seem to have been recorded more than
+// once. Transactions that share a bank record ID are always grouped. Otherwise, transactions with the same amount and
+// merchant are grouped if each happened within the tolerance of the earliest of them, so that a regular purchase made
+// a little more often than the tolerance isn't chained into one ever-growing group. Merges, reverts, and transactions that have
+// already been reverted are ignored.
+func Find(ctx context.Context, loader persist.Loader, tolerance time.Duration, heads ...envelopes.ID) ([]Group, error) {
+	var candidates []Entry
+	reverted := make(map[envelopes.ID]struct{})
+
+	walker := persist.Walker{Loader: loader}
+	err := walker.Walk(ctx, func(_ context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		for _, target := range transaction.Reverts {
+			reverted[target] = struct{}{}
+		}
+		if len(transaction.Reverts) == 0 && len(transaction.Parents) <= 1 {
+			candidates = append(candidates, Entry{ID: id, Transaction: transaction})
+		}
+		return nil
+	}, heads...)
+	if err != nil {
+		return nil, err
+	}
+
+	byRecordID := make(map[envelopes.BankRecordID][]Entry)
+	byDetails := make(map[string][]Entry)
+	for _, candidate := range candidates {
+		if _, ok := reverted[candidate.ID]; ok {
+			continue
+		}
+
+		if candidate.Transaction.RecordID != "" {
+			byRecordID[candidate.Transaction.RecordID] = append(byRecordID[candidate.Transaction.RecordID], candidate)
+		}
+
+		if !candidate.Transaction.Amount.Equal(envelopes.Balance{}) {
+			key := detailsKey(candidate.Transaction)
+			byDetails[key] = append(byDetails[key], candidate)
+		}
+	}
+
+	var retval []Group
+	grouped := make(map[envelopes.ID]struct{})
+
+	for _, entries := range byRecordID {
+		if len(entries) < 2 {
+			continue
+		}
+		for _, entry := range entries {
+			grouped[entry.ID] = struct{}{}
+		}
+		retval = append(retval, newGroup(ReasonRecordID, entries))
+	}
+
+	for _, entries := range byDetails {
+		var remaining []Entry
+		for _, entry := range entries {
+			if _, ok := grouped[entry.ID]; !ok {
+				remaining = append(remaining, entry)
+			}
+		}
+
+		sort.Slice(remaining, func(i, j int) bool {
+			return when(remaining[i].Transaction).Before(when(remaining[j].Transaction))
+		})
+
+		start := 0
+		for i := 1; i <= len(remaining); i++ {
+			if i < len(remaining) && when(remaining[i].Transaction).Sub(when(remaining[start].Transaction)) <= tolerance {
+				continue
+			}
+			if i-start > 1 {
+				retval = append(retval, newGroup(ReasonDetails, remaining[start:i]))
+			}
+			start = i
+		}
+	}
+
+	sort.Slice(retval, func(i, j int) bool {
+		left, right := retval[i].Entries[0], retval[j].Entries[0]
+		if !when(left.Transaction).Equal(when(right.Transaction)) {
+			return when(left.Transaction).Before(when(right.Transaction))
+		}
+		return left.ID.String() < right.ID.String()
+	})
+
+	return retval, nil
+}
+
+func newGroup(reason Reason, entries []Entry) Group {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Transaction.EnteredTime.Before(sorted[j].Transaction.EnteredTime)
+	})
+	return Group{Reason: reason, Entries: sorted}
+}
+
+// when finds the time a transaction happened, preferring the time it actually occurred over when it was posted.
+func when(transaction envelopes.Transaction) time.Time {
+	if !transaction.ActualTime.IsZero() {
+		return transaction.ActualTime
+	}
+	return transaction.PostedTime
+}
+
+// detailsKey identifies transactions with the same amount and merchant. Merchants are compared without regard to case
+// or surrounding whitespace, and amounts are compared exactly.
+func detailsKey(transaction envelopes.Transaction) string {
+	assets := make([]string, 0, len(transaction.Amount))
+	for asset, magnitude := range transaction.Amount {
+		assets = append(assets, string(asset)+" "+magnitude.RatString())
+	}
+	sort.Strings(assets)
+
+	return strings.ToLower(strings.TrimSpace(transaction.Merchant)) + "\n" + strings.Join(assets, "\n")
+}
@@ -0,0 +1,170 @@
+package duplicate
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+func TestFind(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo, err := filesystem.OpenRepository(ctx, t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var head envelopes.ID
+	names := make(map[envelopes.ID]string)
+	entered := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	commit := func(name string, merchant string, dollars int64, posted time.Time, recordID envelopes.BankRecordID, reverts ...envelopes.ID) envelopes.ID {
+		entered = entered.Add(time.Minute)
+		transaction := envelopes.Transaction{
+			State: &envelopes.State{
+				Accounts: envelopes.Accounts{"checking": envelopes.Balance{"USD": big.NewRat(int64(len(names)), 1)}},
+			},
+			PostedTime:  posted,
+			EnteredTime: entered,
+			Amount:      envelopes.Balance{"USD": big.NewRat(dollars, 1)},
+			Merchant:    merchant,
+			RecordID:    recordID,
+			Reverts:     reverts,
+		}
+		if head != (envelopes.ID{}) {
+			transaction.Parents = []envelopes.ID{head}
+		}
+
+		if err := repo.WriteTransaction(ctx, transaction); err != nil {
+			t.Fatal(err)
+		}
+		head = transaction.ID()
+		names[head] = name
+		return head
+	}
+
+	march := func(day int) time.Time {
+		return time.Date(2026, time.March, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	commit("paycheck", "Employer", 100, march(1), "")
+	commit("amazon", "Amazon.com", -43, march(14), "R1")
+	commit("amazon again", "Amazon.com", -43, march(14), "R1")
+	commit("different amazon", "Amazon.com", -12, march(14), "R2")
+	commit("target", "Target", -5, march(20), "")
+	commit("target again", "target ", -5, march(21), "")
+	commit("target next week", "Target", -5, march(28), "")
+	mistake := commit("coffee", "Cafe", -4, march(22), "")
+	commit("coffee again", "Cafe", -4, march(22), "")
+	commit("undo coffee", "", 4, march(23), "", mistake)
+
+	got, err := Find(ctx, repo, 48*time.Hour, head)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []struct {
+		reason Reason
+		names  []string
+	}{
+		{ReasonRecordID, []string{"amazon", "amazon again"}},
+		{ReasonDetails, []string{"target", "target again"}},
+	}
+
+	if len(got) != len(expected) {
+		t.Logf("got %d groups, want %d", len(got), len(expected))
+		t.FailNow()
+	}
+
+	for i, group := range got {
+		var gotNames []string
+		for _, entry := range group.Entries {
+			gotNames = append(gotNames, names[entry.ID])
+		}
+
+		if group.Reason != expected[i].reason || len(gotNames) != len(expected[i].names) {
+			t.Logf("group %d\n\tgot:  %s %v\n\twant: %s %v", i, group.Reason, gotNames, expected[i].reason, expected[i].names)
+			t.Fail()
+			continue
+		}
+
+		for j := range gotNames {
+			if gotNames[j] != expected[i].names[j] {
+				t.Logf("group %d\n\tgot:  %v\n\twant: %v", i, gotNames, expected[i].names)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func TestFind_regularPurchasesDontChain(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo, err := filesystem.OpenRepository(ctx, t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	const tolerance = 48 * time.Hour
+	start := time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+	// The same coffee, bought a little more often than the tolerance. Each is within the tolerance of the one
+	// before it, so chaining them together would make a single group of all four.
+	var head envelopes.ID
+	var ids []envelopes.ID
+	for i := 0; i < 4; i++ {
+		transaction := envelopes.Transaction{
+			State: &envelopes.State{
+				Accounts: envelopes.Accounts{"checking": envelopes.Balance{"USD": big.NewRat(int64(100-4*i), 1)}},
+			},
+			PostedTime:  start.Add(time.Duration(i) * (tolerance - time.Hour)),
+			EnteredTime: start.Add(time.Duration(i) * tolerance),
+			Amount:      envelopes.Balance{"USD": big.NewRat(-4, 1)},
+			Merchant:    "Cafe",
+		}
+		if head != (envelopes.ID{}) {
+			transaction.Parents = []envelopes.ID{head}
+		}
+
+		if err := repo.WriteTransaction(ctx, transaction); err != nil {
+			t.Fatal(err)
+		}
+		head = transaction.ID()
+		ids = append(ids, head)
+	}
+
+	got, err := Find(ctx, repo, tolerance, head)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := [][]envelopes.ID{ids[0:2], ids[2:4]}
+	if len(got) != len(expected) {
+		t.Logf("got %d groups, want %d: %+v", len(got), len(expected), got)
+		t.FailNow()
+	}
+
+	for i, group := range got {
+		if len(group.Entries) != len(expected[i]) {
+			t.Logf("group %d has %d entries, want %d", i, len(group.Entries), len(expected[i]))
+			t.Fail()
+			continue
+		}
+		for j, entry := range group.Entries {
+			if !entry.ID.Equal(expected[i][j]) {
+				t.Logf("group %d entry %d\n\tgot:  %s\n\twant: %s", i, j, entry.ID, expected[i][j])
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package format
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"gopkg.in/yaml.v2"
+)
+
+// OutputFormat identifies how a command should present its results.
+type OutputFormat string
+
+// These are the OutputFormat values that are understood by WriteDocument.
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json"
+	OutputYAML OutputFormat = "yaml"
+)
+
+// ErrUnknownOutputFormat is returned when an OutputFormat is requested that isn't supported.
+type ErrUnknownOutputFormat string
+
+func (e ErrUnknownOutputFormat) Error() string {
+	return fmt.Sprintf("unrecognized output format %q (expected one of: %s, %s, %s)", string(e), OutputText, OutputJSON, OutputYAML)
+}
+
+// ParseOutputFormat interprets the name of an OutputFormat.
+func ParseOutputFormat(raw string) (OutputFormat, error) {
+	switch parsed := OutputFormat(strings.ToLower(strings.TrimSpace(raw))); parsed {
+	case OutputText, OutputJSON, OutputYAML:
+		return parsed, nil
+	default:
+		return "", ErrUnknownOutputFormat(raw)
+	}
+}
+
+// WriteDocument serializes one of the document types in this package as either JSON or YAML.
+func WriteDocument(output io.Writer, outputFormat OutputFormat, document interface{}) error {
+	switch outputFormat {
+	case OutputJSON:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case OutputYAML:
+		marshaled, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		_, err = output.Write(marshaled)
+		return err
+	default:
+		return ErrUnknownOutputFormat(outputFormat)
+	}
+}
+
+// BalanceDocument is the structured form of an envelopes.Balance. Each asset type is mapped to its exact magnitude,
+// written as a rational number like "617/50" or "-7" so that no precision is lost.
+type BalanceDocument map[envelopes.AssetType]string
+
+// NewBalanceDocument converts an envelopes.Balance into its structured form. Asset types with a magnitude of zero are
+// omitted.
+func NewBalanceDocument(subject envelopes.Balance) BalanceDocument {
+	retval := make(BalanceDocument, len(subject))
+	for asset, magnitude := range subject {
+		if magnitude.Sign() == 0 {
+			continue
+		}
+		retval[asset] = magnitude.RatString()
+	}
+	return retval
+}
+
+// ImpactDocument is the structured form of an envelopes.Impact. Budgets are flattened, and keyed the same way
+// PrettyPrintImpact names them.
+type ImpactDocument struct {
+	Accounts map[string]BalanceDocument `json:"accounts" yaml:"accounts"`
+	Budgets  map[string]BalanceDocument `json:"budgets" yaml:"budgets"`
+}
+
+// NewImpactDocument converts an envelopes.Impact into its structured form.
+func NewImpactDocument(impacts envelopes.Impact) ImpactDocument {
+	retval := ImpactDocument{
+		Accounts: make(map[string]BalanceDocument, len(impacts.Accounts)),
+		Budgets:  make(map[string]BalanceDocument),
+	}
+
+	for name, delta := range impacts.Accounts {
+		retval.Accounts[name] = NewBalanceDocument(delta)
+	}
+
+	for name, delta := range FlattenBudgets(impacts) {
+		retval.Budgets[name] = NewBalanceDocument(delta)
+	}
+
+	return retval
+}
+
+// TransactionDocument is the structured form of an envelopes.Transaction.
+type TransactionDocument struct {
+	ID          string          `json:"id" yaml:"id"`
+	ActualTime  *time.Time      `json:"actual_time,omitempty" yaml:"actual_time,omitempty"`
+	PostedTime  *time.Time      `json:"posted_time,omitempty" yaml:"posted_time,omitempty"`
+	EnteredTime *time.Time      `json:"entered_time,omitempty" yaml:"entered_time,omitempty"`
+	Amount      BalanceDocument `json:"amount" yaml:"amount"`
+	Merchant    string          `json:"merchant" yaml:"merchant"`
+	RecordID    string          `json:"bank_record_id,omitempty" yaml:"bank_record_id,omitempty"`
+	Parents     []string        `json:"parents" yaml:"parents"`
+	Reverts     []string        `json:"reverts,omitempty" yaml:"reverts,omitempty"`
+	Comment     string          `json:"comment" yaml:"comment"`
+	Impacts     *ImpactDocument `json:"impacts,omitempty" yaml:"impacts,omitempty"`
+}
+
+// NewTransactionDocument converts an envelopes.Transaction into its structured form, without its impacts.
+func NewTransactionDocument(subject envelopes.Transaction) TransactionDocument {
+	retval := TransactionDocument{
+		ID:       subject.ID().String(),
+		Amount:   NewBalanceDocument(subject.Amount),
+		Merchant: subject.Merchant,
+		RecordID: subject.RecordID.String(),
+		Parents:  make([]string, 0, len(subject.Parents)),
+		Reverts:  idStrings(subject.Reverts),
+		Comment:  subject.Comment,
+	}
+
+	retval.Parents = append(retval.Parents, idStrings(subject.Parents)...)
+
+	if !subject.ActualTime.Equal(time.Time{}) {
+		actual := subject.ActualTime
+		retval.ActualTime = &actual
+	}
+	if !subject.PostedTime.Equal(time.Time{}) {
+		posted := subject.PostedTime
+		retval.PostedTime = &posted
+	}
+	if !subject.EnteredTime.Equal(time.Time{}) {
+		entered := subject.EnteredTime
+		retval.EnteredTime = &entered
+	}
+
+	return retval
+}
+
+// NewDetailedTransactionDocument converts an envelopes.Transaction into its structured form, including the impacts
+// it had on each account and budget. Like PrettyPrintTransaction, it needs a persist.Loader to find its parent.
+func NewDetailedTransactionDocument(ctx context.Context, loader persist.Loader, subject envelopes.Transaction) (TransactionDocument, error) {
+	retval := NewTransactionDocument(subject)
+
+	impacts, err := loadParentImpact(ctx, loader, subject)
+	if err != nil {
+		return TransactionDocument{}, err
+	}
+
+	impactDoc := NewImpactDocument(impacts)
+	retval.Impacts = &impactDoc
+	return retval, nil
+}
+
+// BudgetDocument is the structured form of the balances that the "balance" command shows for a budget.
+type BudgetDocument struct {
+	Total    BalanceDocument            `json:"total" yaml:"total"`
+	Balance  BalanceDocument            `json:"balance" yaml:"balance"`
+	Children map[string]BalanceDocument `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// NewBudgetDocument converts an envelopes.Budget into its structured form. Children are summarized by their
+// recursive balance.
+func NewBudgetDocument(subject envelopes.Budget) BudgetDocument {
+	retval := BudgetDocument{
+		Total:   NewBalanceDocument(subject.RecursiveBalance()),
+		Balance: NewBalanceDocument(subject.Balance),
+	}
+
+	if len(subject.Children) > 0 {
+		retval.Children = make(map[string]BalanceDocument, len(subject.Children))
+		for name, child := range subject.Children {
+			retval.Children[name] = NewBalanceDocument(child.RecursiveBalance())
+		}
+	}
+
+	return retval
+}
+
+// BalancesDocument is the structured form of the output of the "balance" command.
+type BalancesDocument struct {
+	Accounts map[string]BalanceDocument `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	Budget   *BudgetDocument            `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// NewAccountsDocument converts envelopes.Accounts into a map of their structured balances.
+func NewAccountsDocument(subject envelopes.Accounts) map[string]BalanceDocument {
+	retval := make(map[string]BalanceDocument, len(subject))
+	for name, bal := range subject {
+		retval[name] = NewBalanceDocument(bal)
+	}
+	return retval
+}
+
+func idStrings(subject []envelopes.ID) []string {
+	if len(subject) == 0 {
+		return nil
+	}
+
+	retval := make([]string, len(subject))
+	for i := range subject {
+		retval[i] = subject[i].String()
+	}
+	return retval
+}
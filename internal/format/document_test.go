@@ -0,0 +1,106 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestWriteDocument(t *testing.T) {
+	subject := BalancesDocument{
+		Accounts: NewAccountsDocument(envelopes.Accounts{
+			"checking": {"USD": big.NewRat(1234, 100), "EUR": big.NewRat(-7, 1), "CAD": big.NewRat(0, 1)},
+		}),
+		Budget: &BudgetDocument{
+			Total:   NewBalanceDocument(envelopes.Balance{"USD": big.NewRat(1, 3)}),
+			Balance: NewBalanceDocument(envelopes.Balance{}),
+		},
+	}
+
+	testCases := []struct {
+		format   OutputFormat
+		expected string
+	}{
+		{
+			OutputJSON,
+			`{
+  "accounts": {
+    "checking": {
+      "EUR": "-7",
+      "USD": "617/50"
+    }
+  },
+  "budget": {
+    "total": {
+      "USD": "1/3"
+    },
+    "balance": {}
+  }
+}
+`,
+		},
+		{
+			OutputYAML,
+			`accounts:
+  checking:
+    EUR: "-7"
+    USD: 617/50
+budget:
+  total:
+    USD: 1/3
+  balance: {}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		output := &bytes.Buffer{}
+		err := WriteDocument(output, tc.format, subject)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got := output.String(); got != tc.expected {
+			t.Logf("%s\ngot:\n%s\nwant:\n%s", tc.format, got, tc.expected)
+			t.Fail()
+		}
+	}
+
+	err := WriteDocument(&bytes.Buffer{}, OutputText, subject)
+	if !errors.As(err, new(ErrUnknownOutputFormat)) {
+		t.Logf("expected text to be rejected as a document format, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected OutputFormat
+	}{
+		{"text", OutputText},
+		{"JSON", OutputJSON},
+		{" yaml ", OutputYAML},
+		{"xml", ""},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseOutputFormat(tc.raw)
+		if tc.expected == "" {
+			if !errors.As(err, new(ErrUnknownOutputFormat)) {
+				t.Logf("expected %q to be rejected, got: %v", tc.raw, err)
+				t.Fail()
+			}
+			continue
+		}
+
+		if err != nil || got != tc.expected {
+			t.Logf("%q\n\tgot:  %q (error: %v)\n\twant: %q", tc.raw, got, err, tc.expected)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package format
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+)
+
+// These are the names of the templates that can be passed to ParseTransactionTemplate in place of a template of one's
+// own.
+const (
+	PresetOneline = "oneline"
+	PresetShort   = "short"
+	PresetFull    = "full"
+	PresetLedger  = "ledger"
+)
+
+// presets are the templates behind each of the preset names. The ledger preset writes entries that can be read by
+// ledger-cli: accounts are real postings, budgets are virtual postings, and whatever is left over is balanced against
+// the merchant.
+var presets = map[string]string{
+	PresetOneline: `{{short .ID}} {{date "2006-01-02" .PostedTime}} {{.Amount}} {{.Merchant}}{{with .Comment}} ({{.}}){{end}}`,
+	PresetShort: `{{.ID}}
+	Posted:  	{{date "2006-01-02" .PostedTime}}
+	Amount:  	{{.Amount}}
+	Merchant:	{{.Merchant}}
+{{- with .Comment}}
+	Comment: 	{{.}}{{end}}`,
+	PresetFull: `{{.ID}}
+{{details .}}`,
+	PresetLedger: `{{date "2006/01/02" .PostedTime}}{{if not .ActualTime.IsZero}}={{date "2006/01/02" .ActualTime}}{{end}}{{with .RecordID}} ({{.}}){{end}} {{.Merchant}}
+{{- with .Comment}}
+    ; {{.}}{{end}}
+{{- with .Impact}}
+{{- range postings "Assets" .Accounts}}
+    {{printf "%-40s" .Account}}  {{.Amount}}{{end}}
+{{- range postings "Budget" (budgets .)}}
+    {{printf "%-40s" (printf "(%s)" .Account)}}  {{.Amount}}{{end}}
+{{- end}}
+    Payees:{{or .Merchant "Unknown"}}`,
+}
+
+// TransactionTemplate writes each transaction it is given according to a Go text/template. The template is executed
+// against the fields of an envelopes.Transaction. It may also use:
+//   - .ID, the ID of the transaction.
+//   - .Impact, the change the transaction made to each account and budget.
+//   - date, which formats a time using a Go layout string, or writes nothing if the time wasn't recorded.
+//   - short, which abbreviates an ID.
+//   - ids, which joins a list of IDs with commas.
+//   - budgets, which flattens the budgets of an Impact into a map keyed by their slash separated names.
+//   - postings, which lists each asset of a map of Balances under the given top-level ledger account.
+//   - details, which writes everything that "show" would about a transaction.
+type TransactionTemplate struct {
+	parsed *template.Template
+}
+
+// ParseTransactionTemplate reads a template for printing transactions. The names of the presets above are accepted
+// as well as templates of one's own.
+func ParseTransactionTemplate(raw string) (*TransactionTemplate, error) {
+	if preset, ok := presets[raw]; ok {
+		raw = preset
+	}
+
+	parsed, err := template.New("transaction").Funcs(template.FuncMap{
+		"date":     formatDate,
+		"short":    shortID,
+		"ids":      func(subject []envelopes.ID) string { return joinedIDStringList(subject, ", ") },
+		"budgets":  FlattenBudgets,
+		"postings": postings,
+		"details":  details,
+	}).Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionTemplate{parsed: parsed}, nil
+}
+
+// Execute writes a single transaction, followed by a newline. The loader is used to fetch the parent of the
+// transaction if the template needs to know its impact.
+func (t *TransactionTemplate) Execute(ctx context.Context, output io.Writer, loader persist.Loader, subject envelopes.Transaction) error {
+	view := &transactionView{
+		Transaction: subject,
+		ctx:         ctx,
+		loader:      loader,
+	}
+
+	buf := &strings.Builder{}
+	err := t.parsed.Execute(buf, view)
+	if err != nil {
+		return err
+	}
+
+	written := buf.String()
+	if !strings.HasSuffix(written, "\n") {
+		written += "\n"
+	}
+	_, err = io.WriteString(output, written)
+	return err
+}
+
+// transactionView is what a TransactionTemplate is executed against. It computes the impact of a transaction only if
+// the template asks for it, because doing so requires loading its parent.
+type transactionView struct {
+	envelopes.Transaction
+	ctx    context.Context
+	loader persist.Loader
+	impact *envelopes.Impact
+}
+
+// Impact finds the change this transaction made to each account and budget.
+func (v *transactionView) Impact() (envelopes.Impact, error) {
+	if v.impact == nil {
+		impact, err := loadParentImpact(v.ctx, v.loader, v.Transaction)
+		if err != nil {
+			return envelopes.Impact{}, err
+		}
+		v.impact = &impact
+	}
+	return *v.impact, nil
+}
+
+// Posting is a single line of a ledger transaction, moving an amount of one asset in or out of an account.
+type Posting struct {
+	Account string
+	Amount  string
+}
+
+func postings(root string, balances map[string]envelopes.Balance) []Posting {
+	names := make([]string, 0, len(balances))
+	for name := range balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var retval []Posting
+	for _, name := range names {
+		account := root
+		for _, segment := range strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/") {
+			if segment != "" {
+				account += ":" + segment
+			}
+		}
+
+		assets := make([]string, 0, len(balances[name]))
+		for asset := range balances[name] {
+			assets = append(assets, string(asset))
+		}
+		sort.Strings(assets)
+
+		for _, asset := range assets {
+			magnitude := balances[name][envelopes.AssetType(asset)]
+			if magnitude == nil || magnitude.Sign() == 0 {
+				continue
+			}
+			retval = append(retval, Posting{
+				Account: account,
+				Amount:  fmt.Sprintf("%s %s", decimalString(magnitude), asset),
+			})
+		}
+	}
+	return retval
+}
+
+// decimalString writes a rational number with as few decimal places as it can without losing precision, but never
+// fewer than two. Numbers that can't be written exactly are rounded to eight places.
+func decimalString(subject *big.Rat) string {
+	const minPrecision, maxPrecision = 2, 8
+	for precision := minPrecision; precision < maxPrecision; precision++ {
+		written := subject.FloatString(precision)
+		if parsed, ok := new(big.Rat).SetString(written); ok && parsed.Cmp(subject) == 0 {
+			return written
+		}
+	}
+	return subject.FloatString(maxPrecision)
+}
+
+func formatDate(layout string, subject time.Time) string {
+	if subject.IsZero() {
+		return ""
+	}
+	return subject.Format(layout)
+}
+
+func shortID(subject envelopes.ID) string {
+	const length = 7
+	return subject.String()[:length]
+}
+
+func details(view *transactionView) (string, error) {
+	buf := &strings.Builder{}
+	err := PrettyPrintTransaction(view.ctx, buf, view.loader, view.Transaction)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
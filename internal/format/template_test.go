@@ -0,0 +1,69 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestTransactionTemplate_Execute(t *testing.T) {
+	subject := envelopes.Transaction{
+		State: &envelopes.State{
+			Accounts: envelopes.Accounts{"bank/checking": envelopes.Balance{"USD": big.NewRat(-4299, 100)}},
+			Budget: &envelopes.Budget{
+				Children: map[string]*envelopes.Budget{
+					"shopping": {Balance: envelopes.Balance{"USD": big.NewRat(-4299, 100)}},
+				},
+			},
+		},
+		PostedTime: time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC),
+		Amount:     envelopes.Balance{"USD": big.NewRat(-4299, 100)},
+		Merchant:   "Amazon.com",
+		RecordID:   "R1",
+	}
+
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{
+			PresetOneline,
+			shortID(subject.ID()) + " 2026-03-14 USD -42.990 Amazon.com\n",
+		},
+		{
+			PresetLedger,
+			"2026/03/14 (R1) Amazon.com\n" +
+				"    Assets:bank:checking                      -42.99 USD\n" +
+				"    (Budget:shopping)                         -42.99 USD\n" +
+				"    Payees:Amazon.com\n",
+		},
+		{
+			`{{.Merchant}} paid {{date "Jan 2" .ActualTime}}`,
+			"Amazon.com paid \n",
+		},
+	}
+
+	for _, tc := range testCases {
+		parsed, err := ParseTransactionTemplate(tc.template)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		output := &bytes.Buffer{}
+		err = parsed.Execute(context.Background(), output, nil, subject)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got := output.String(); got != tc.expected {
+			t.Logf("%s\n\tgot:  %q\n\twant: %q", tc.template, got, tc.expected)
+			t.Fail()
+		}
+	}
+}
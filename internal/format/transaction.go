@@ -95,18 +95,9 @@ func PrettyPrintTransaction(
 	output io.Writer,
 	loader persist.Loader,
 	subject envelopes.Transaction) error {
-	var err error
-	var impacts envelopes.Impact
-
-	if subject.Parents[0].Equal(envelopes.ID{}) {
-		impacts = envelopes.Impact(*subject.State)
-	} else {
-		var parent envelopes.Transaction
-		err := loader.LoadTransaction(ctx, subject.Parents[0], &parent)
-		if err != nil {
-			return err
-		}
-		impacts = subject.State.Subtract(*parent.State)
+	impacts, err := loadParentImpact(ctx, loader, subject)
+	if err != nil {
+		return err
 	}
 
 	if !subject.ActualTime.Equal(time.Time{}) {
@@ -176,7 +167,7 @@ func PrettyPrintImpact(output io.Writer, impacts envelopes.Impact) (err error) {
 		}
 	}
 
-	flattened := flattenBudgets(impacts)
+	flattened := FlattenBudgets(impacts)
 
 	sortedBudgetNames := make([]string, 0, len(flattened))
 	for name := range flattened {
@@ -195,7 +186,24 @@ func PrettyPrintImpact(output io.Writer, impacts envelopes.Impact) (err error) {
 	return
 }
 
-func flattenBudgets(diff envelopes.Impact) map[string]envelopes.Balance {
+// loadParentImpact finds the difference between a transaction and its first parent. Transactions without a parent
+// are treated as though they were applied to an empty State.
+func loadParentImpact(ctx context.Context, loader persist.Loader, subject envelopes.Transaction) (envelopes.Impact, error) {
+	if len(subject.Parents) == 0 || subject.Parents[0].Equal(envelopes.ID{}) {
+		return envelopes.Impact(*subject.State), nil
+	}
+
+	var parent envelopes.Transaction
+	err := loader.LoadTransaction(ctx, subject.Parents[0], &parent)
+	if err != nil {
+		return envelopes.Impact{}, err
+	}
+	return subject.State.Subtract(*parent.State), nil
+}
+
+// FlattenBudgets lists the change to the balance of each budget in an envelopes.Impact, keyed by the slash separated
+// path of the budget. The root budget is named with an empty string. Budgets that did not change are omitted.
+func FlattenBudgets(diff envelopes.Impact) map[string]envelopes.Balance {
 	retval := make(map[string]envelopes.Balance)
 	var helper func(*envelopes.Budget, string, string)
 	helper = func(current *envelopes.Budget, running, name string) {
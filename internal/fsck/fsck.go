@@ -0,0 +1,306 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package fsck looks for damage to the history stored in a repository, such as transactions that have gone missing or
+// whose contents no longer match the ID they were stored under.
+package fsck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/marstr/envelopes/persist/filesystem"
+
+	"github.com/marstr/baronial/internal/pack"
+)
+
+// Severity describes how worrying a Problem is.
+type Severity int
+
+const (
+	// SeverityWarning is for a Problem that baronial allows to be written, but that may be a mistake.
+	SeverityWarning Severity = iota
+
+	// SeverityError is for a Problem that means part of the history has been lost or damaged.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Problem describes something that is wrong with a Transaction.
+type Problem struct {
+	Transaction envelopes.ID
+	Severity    Severity
+	Description string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: transaction %s: %s", p.Severity, p.Transaction, p.Description)
+}
+
+// Report summarizes everything that was found while checking a repository.
+type Report struct {
+	// Transactions is the number of transactions that were reachable from the heads that were checked.
+	Transactions int
+
+	// Problems are sorted with errors first.
+	Problems []Problem
+
+	// Unreachable lists the transactions that are stored in the repository, but aren't in the history of any head.
+	Unreachable []envelopes.ID
+
+	// Dangling lists the stored objects that are neither part of a transaction, nor a transaction themselves.
+	Dangling []envelopes.ID
+}
+
+// Errors counts the Problems that are errors, rather than warnings.
+func (r Report) Errors() int {
+	count := 0
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// tolerantLoader keeps a persist.Walker going when a Transaction can't be loaded. The Transaction is recorded as
+// missing, and handed to the Walker without any parents.
+type tolerantLoader struct {
+	persist.Loader
+	missing map[envelopes.ID]error
+}
+
+func (l tolerantLoader) LoadTransaction(ctx context.Context, id envelopes.ID, toLoad *envelopes.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := l.Loader.LoadTransaction(ctx, id, toLoad)
+	if err != nil {
+		l.missing[id] = err
+		*toLoad = envelopes.Transaction{}
+	}
+	return nil
+}
+
+// Check walks the history of each head, making sure that every Transaction can be loaded along with its parents and the
+// transactions it reverts, that it's stored under its own ID, that its amount matches the change from its first
+// parent, and that its accounts and budget balance. Afterwards, every object in the repository, packed or not, is
+// listed to find those that aren't reachable from any head.
+func Check(ctx context.Context, repo *filesystem.Repository, heads map[string]envelopes.ID) (Report, error) {
+	var report Report
+
+	names := make([]string, 0, len(heads))
+	starts := make([]envelopes.ID, 0, len(heads))
+	for name, head := range heads {
+		names = append(names, name)
+		starts = append(starts, head)
+	}
+	sort.Strings(names)
+
+	reachable, missing, err := walk(ctx, repo, starts, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) {
+		report.Transactions++
+		report.Problems = append(report.Problems, checkTransaction(ctx, repo, id, transaction)...)
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	for _, name := range names {
+		if err, ok := missing[heads[name]]; ok {
+			report.Problems = append(report.Problems, Problem{
+				Transaction: heads[name],
+				Severity:    SeverityError,
+				Description: fmt.Sprintf("%s points to it, but it can't be loaded: %v", name, err),
+			})
+		}
+	}
+
+	err = findUnreachable(ctx, repo, reachable, &report)
+	if err != nil {
+		return Report{}, err
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		return report.Problems[i].Severity > report.Problems[j].Severity
+	})
+
+	return report, nil
+}
+
+// Reachable finds the ID of every object that is part of the history of heads. Transactions in that history that
+// couldn't be loaded are returned separately, along with the reason why.
+func Reachable(ctx context.Context, loader persist.Loader, heads ...envelopes.ID) (map[envelopes.ID]struct{}, map[envelopes.ID]error, error) {
+	return walk(ctx, loader, heads, nil)
+}
+
+// walk visits every Transaction in the history of heads that can be loaded, and collects the IDs of the objects that
+// are stored as part of them.
+func walk(ctx context.Context, loader persist.Loader, heads []envelopes.ID, visit func(context.Context, envelopes.ID, envelopes.Transaction)) (map[envelopes.ID]struct{}, map[envelopes.ID]error, error) {
+	reachable := make(map[envelopes.ID]struct{})
+	missing := make(map[envelopes.ID]error)
+
+	starts := make([]envelopes.ID, 0, len(heads))
+	for _, head := range heads {
+		if !head.Equal(envelopes.ID{}) {
+			starts = append(starts, head)
+		}
+	}
+
+	walker := persist.Walker{Loader: tolerantLoader{Loader: loader, missing: missing}}
+	err := walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		if _, ok := missing[id]; ok {
+			return persist.ErrSkipAncestors{}
+		}
+
+		markReachable(reachable, id, transaction)
+		if visit != nil {
+			visit(ctx, id, transaction)
+		}
+		return nil
+	}, starts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reachable, missing, nil
+}
+
+// checkTransaction finds everything that is wrong with a single Transaction that was loaded successfully.
+func checkTransaction(ctx context.Context, loader persist.Loader, id envelopes.ID, transaction envelopes.Transaction) []Problem {
+	var retval []Problem
+	report := func(severity Severity, format string, args ...interface{}) {
+		retval = append(retval, Problem{Transaction: id, Severity: severity, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if actual := transaction.ID(); !actual.Equal(id) {
+		report(SeverityError, "it is stored under the wrong ID, its contents belong to %s", actual)
+	}
+
+	previous := envelopes.State{Accounts: envelopes.Accounts{}, Budget: &envelopes.Budget{}}
+	previousFound := true
+	for i, parent := range transaction.Parents {
+		var loaded envelopes.Transaction
+		if err := loader.LoadTransaction(ctx, parent, &loaded); err != nil {
+			report(SeverityError, "its parent %s can't be loaded: %v", parent, err)
+			if i == 0 {
+				previousFound = false
+			}
+			continue
+		}
+
+		if i == 0 && loaded.State != nil {
+			previous = *loaded.State
+		}
+	}
+
+	for _, reverted := range transaction.Reverts {
+		var loaded envelopes.Transaction
+		if err := loader.LoadTransaction(ctx, reverted, &loaded); err != nil {
+			report(SeverityError, "it reverts %s, which can't be loaded: %v", reverted, err)
+		}
+	}
+
+	if transaction.State == nil {
+		return retval
+	}
+
+	if previousFound {
+		if expected := envelopes.CalculateAmount(previous, *transaction.State); !expected.Equal(transaction.Amount) {
+			report(SeverityWarning, "its amount is %s, but the change from its parent is %s", transaction.Amount, expected)
+		}
+	}
+
+	accounts := transaction.State.Accounts.Balance()
+	var budget envelopes.Balance
+	if transaction.State.Budget != nil {
+		budget = transaction.State.Budget.RecursiveBalance()
+	}
+	if !accounts.Equal(budget) {
+		report(SeverityWarning, "its accounts (%s) and budget (%s) don't balance, they're off by %s", accounts, budget, accounts.Sub(budget))
+	}
+
+	return retval
+}
+
+// markReachable records the IDs of every object that is stored as part of a Transaction.
+func markReachable(reachable map[envelopes.ID]struct{}, id envelopes.ID, transaction envelopes.Transaction) {
+	reachable[id] = struct{}{}
+	if transaction.State == nil {
+		return
+	}
+
+	reachable[transaction.State.ID()] = struct{}{}
+	reachable[transaction.State.Accounts.ID()] = struct{}{}
+
+	var helper func(*envelopes.Budget)
+	helper = func(budget *envelopes.Budget) {
+		if budget == nil {
+			return
+		}
+		reachable[budget.ID()] = struct{}{}
+		for _, child := range budget.Children {
+			helper(child)
+		}
+	}
+	helper(transaction.State.Budget)
+}
+
+// findUnreachable lists every object in the repository that isn't reachable, sorting them into the transactions that
+// were orphaned, and everything else.
+func findUnreachable(ctx context.Context, repo *filesystem.Repository, reachable map[envelopes.ID]struct{}, report *Report) error {
+	stored, err := pack.ListObjects(repo.Root)
+	if err != nil {
+		return err
+	}
+
+	var leftover []envelopes.ID
+	for _, id := range stored {
+		if _, ok := reachable[id]; ok {
+			continue
+		}
+
+		var transaction envelopes.Transaction
+		if repo.LoadTransaction(ctx, id, &transaction) == nil && transaction.ID().Equal(id) {
+			report.Unreachable = append(report.Unreachable, id)
+			markReachable(reachable, id, transaction)
+			continue
+		}
+		leftover = append(leftover, id)
+	}
+
+	// An object that belongs to an unreachable Transaction isn't dangling on its own.
+	for _, id := range leftover {
+		if _, ok := reachable[id]; !ok {
+			report.Dangling = append(report.Dangling, id)
+		}
+	}
+	return ctx.Err()
+}
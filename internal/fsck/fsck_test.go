@@ -0,0 +1,113 @@
+package fsck
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+func usd(dollars int64) envelopes.Balance {
+	return envelopes.Balance{"USD": big.NewRat(dollars, 1)}
+}
+
+func commit(t *testing.T, repo *filesystem.Repository, accounts, budget int64, parents ...envelopes.ID) envelopes.ID {
+	var previous envelopes.State
+	if len(parents) > 0 {
+		var parent envelopes.Transaction
+		if err := repo.LoadTransaction(context.Background(), parents[0], &parent); err != nil {
+			t.Fatal(err)
+		}
+		previous = *parent.State
+	}
+
+	state := envelopes.State{
+		Accounts: envelopes.Accounts{"checking": usd(accounts)},
+		Budget:   &envelopes.Budget{Balance: usd(budget)},
+	}
+
+	transaction := envelopes.Transaction{
+		State:       &state,
+		Amount:      envelopes.CalculateAmount(previous, state),
+		Parents:     parents,
+		EnteredTime: time.Date(2026, time.October, 17, 0, 0, int(accounts+budget), 0, time.UTC),
+	}
+
+	err := repo.WriteTransaction(context.Background(), transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction.ID()
+}
+
+func TestCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo, err := filesystem.OpenRepository(ctx, t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	first := commit(t, repo, 100, 100)
+	second := commit(t, repo, 90, 90, first)
+	third := commit(t, repo, 80, 70, second)
+	orphan := commit(t, repo, 60, 60, first)
+
+	report, err := Check(ctx, repo, map[string]envelopes.ID{"master": third})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if report.Transactions != 3 || report.Errors() != 0 {
+		t.Logf("got %d transaction(s) and %d error(s), want 3 and 0: %v", report.Transactions, report.Errors(), report.Problems)
+		t.Fail()
+	}
+
+	if len(report.Problems) != 1 || !report.Problems[0].Transaction.Equal(third) {
+		t.Logf("expected only the imbalance in the third transaction to be reported, got: %v", report.Problems)
+		t.Fail()
+	}
+
+	if len(report.Unreachable) != 1 || !report.Unreachable[0].Equal(orphan) {
+		t.Logf("expected only %s to be unreachable, got: %v", orphan, report.Unreachable)
+		t.Fail()
+	}
+
+	if len(report.Dangling) != 0 {
+		t.Logf("expected no dangling objects, got: %v", report.Dangling)
+		t.Fail()
+	}
+
+	// Lose the second transaction, the way a botched sync might.
+	raw := second.String()
+	err = os.Remove(filepath.Join(repo.Root, filesystem.ObjectsDir, raw[:2], raw[2:]+".json"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	report, err = Check(ctx, repo, map[string]envelopes.ID{"master": third})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if report.Errors() != 1 || report.Problems[0].Severity != SeverityError || !report.Problems[0].Transaction.Equal(third) {
+		t.Logf("expected the missing parent of %s to be reported, got: %v", third, report.Problems)
+		t.Fail()
+	}
+
+	// The lost transaction's state, accounts and budget are all left behind.
+	if len(report.Dangling) != 3 {
+		t.Logf("expected the state of the lost transaction to be left dangling, got: %v", report.Dangling)
+		t.Fail()
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package graph draws the history of a repository as text, with a lane for each line of ancestry so that it is visible
+// where branches diverged and where they were merged back together.
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// Node is a transaction as it appears in a graph.
+type Node struct {
+	ID envelopes.ID
+
+	// Parents are the nodes this one should be drawn connected to.
+	Parents []envelopes.ID
+
+	// Time decides which node is drawn first when neither is an ancestor of the other. More recent nodes come first.
+	Time time.Time
+}
+
+// Sort orders nodes so that each one comes before all of its parents. Parents which aren't among the nodes are
+// ignored.
+func Sort(nodes map[envelopes.ID]Node) []Node {
+	children := make(map[envelopes.ID]int, len(nodes))
+	for _, node := range nodes {
+		for _, parent := range node.Parents {
+			if _, ok := nodes[parent]; ok {
+				children[parent]++
+			}
+		}
+	}
+
+	ready := &nodeHeap{}
+	for id, node := range nodes {
+		if children[id] == 0 {
+			heap.Push(ready, node)
+		}
+	}
+
+	retval := make([]Node, 0, len(nodes))
+	for ready.Len() > 0 {
+		current := heap.Pop(ready).(Node)
+		retval = append(retval, current)
+		for _, parent := range current.Parents {
+			if _, ok := nodes[parent]; !ok {
+				continue
+			}
+			children[parent]--
+			if children[parent] == 0 {
+				heap.Push(ready, nodes[parent])
+			}
+		}
+	}
+	return retval
+}
+
+// Simplify removes the nodes that shouldn't be shown, connecting each of those that remain to its nearest ancestors
+// that are also being shown.
+func Simplify(nodes map[envelopes.ID]Node, keep func(envelopes.ID) bool) map[envelopes.ID]Node {
+	sorted := Sort(nodes)
+
+	// Because parents are sorted after their children, visiting the list backwards means that the nearest kept
+	// ancestors of every parent are already known.
+	nearest := make(map[envelopes.ID][]envelopes.ID, len(sorted))
+	retval := make(map[envelopes.ID]Node)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		current := sorted[i]
+
+		var parents []envelopes.ID
+		seen := make(map[envelopes.ID]struct{})
+		add := func(id envelopes.ID) {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				parents = append(parents, id)
+			}
+		}
+
+		for _, parent := range current.Parents {
+			if _, ok := nodes[parent]; !ok {
+				continue
+			}
+			if keep(parent) {
+				add(parent)
+				continue
+			}
+			for _, ancestor := range nearest[parent] {
+				add(ancestor)
+			}
+		}
+
+		nearest[current.ID] = parents
+		if keep(current.ID) {
+			current.Parents = parents
+			retval[current.ID] = current
+		}
+	}
+	return retval
+}
+
+// Graph draws nodes one at a time, in the order produced by Sort, remembering which lanes are still waiting for an
+// ancestor to be drawn.
+type Graph struct {
+	lanes []envelopes.ID
+}
+
+// Write draws the next node, with its text beside it. Text that spans several lines is continued alongside the lanes
+// that are still open.
+func (g *Graph) Write(output io.Writer, node Node, text string) error {
+	before, row, after := g.next(node)
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	for _, line := range before {
+		_, err := fmt.Fprintln(output, strings.TrimRight(line, " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(output, row+lines[0])
+	if err != nil {
+		return err
+	}
+	lines = lines[1:]
+
+	for _, line := range after {
+		if len(lines) > 0 {
+			_, err = fmt.Fprintln(output, line+lines[0])
+			lines = lines[1:]
+		} else {
+			_, err = fmt.Fprintln(output, strings.TrimRight(line, " "))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	padding := g.draw(func(canvas []byte) {
+		for i := range g.lanes {
+			canvas[2*i] = '|'
+		}
+	}, len(g.lanes))
+	for _, line := range lines {
+		_, err = fmt.Fprintln(output, padding+line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next updates the lanes to account for a node. It returns the lines to draw before the node, where lanes that were
+// waiting for it come together, the line with the node itself, and the lines to draw after it, where lanes are opened
+// for a merge's parents or closed when a line of ancestry ends.
+func (g *Graph) next(node Node) (before []string, row string, after []string) {
+	col := -1
+	for i := 0; i < len(g.lanes); {
+		if !g.lanes[i].Equal(node.ID) {
+			i++
+			continue
+		}
+		if col < 0 {
+			col = i
+			i++
+			continue
+		}
+		before = append(before, g.collapse(col, i))
+		g.lanes = append(g.lanes[:i], g.lanes[i+1:]...)
+	}
+
+	if col < 0 {
+		g.lanes = append(g.lanes, node.ID)
+		col = len(g.lanes) - 1
+	}
+
+	row = g.draw(func(canvas []byte) {
+		for i := range g.lanes {
+			canvas[2*i] = '|'
+		}
+		canvas[2*col] = '*'
+	}, len(g.lanes))
+
+	if len(node.Parents) == 0 {
+		if col < len(g.lanes)-1 {
+			after = append(after, g.collapse(-1, col))
+		}
+		g.lanes = append(g.lanes[:col], g.lanes[col+1:]...)
+		return
+	}
+
+	g.lanes[col] = node.Parents[0]
+	for _, parent := range node.Parents[1:] {
+		after = append(after, g.expand(col))
+		g.lanes = append(g.lanes[:col+1], append([]envelopes.ID{parent}, g.lanes[col+1:]...)...)
+	}
+	return
+}
+
+// collapse draws lane "closing" moving over to meet lane "target", with the lanes to its right shifting over to fill
+// the gap. A target of -1 draws the lane as having ended instead.
+func (g *Graph) collapse(target, closing int) string {
+	return g.draw(func(canvas []byte) {
+		for i := 0; i < closing; i++ {
+			canvas[2*i] = '|'
+		}
+		for i := target; i >= 0 && i < closing-1; i++ {
+			canvas[2*i+1] = '_'
+		}
+		if target < 0 {
+			canvas[2*closing] = ' '
+		} else {
+			canvas[2*closing-1] = '/'
+		}
+		for i := closing + 1; i < len(g.lanes); i++ {
+			canvas[2*i-1] = '/'
+		}
+	}, len(g.lanes))
+}
+
+// expand draws a new lane branching off to the right of lane "from", with the lanes already to its right shifting
+// over to make room.
+func (g *Graph) expand(from int) string {
+	return g.draw(func(canvas []byte) {
+		for i := 0; i <= from; i++ {
+			canvas[2*i] = '|'
+		}
+		for i := from; i < len(g.lanes); i++ {
+			canvas[2*i+1] = '\\'
+		}
+	}, len(g.lanes)+1)
+}
+
+func (g *Graph) draw(paint func([]byte), width int) string {
+	canvas := []byte(strings.Repeat(" ", 2*width))
+	paint(canvas)
+	return string(canvas)
+}
+
+// nodeHeap keeps the most recent Node on top.
+type nodeHeap []Node
+
+func (h nodeHeap) Len() int { return len(h) }
+
+func (h nodeHeap) Less(i, j int) bool {
+	if !h[i].Time.Equal(h[j].Time) {
+		return h[i].Time.After(h[j].Time)
+	}
+	return h[i].ID.String() < h[j].ID.String()
+}
+
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *nodeHeap) Push(x any) { *h = append(*h, x.(Node)) }
+
+func (h *nodeHeap) Pop() any {
+	old := *h
+	retval := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return retval
+}
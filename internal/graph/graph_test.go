@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// history builds a map of Nodes from a list of names and the names of their parents. Each Node is given a time
+// earlier than the one before it, so they are listed from newest to oldest.
+func history(entries ...[]string) (map[envelopes.ID]Node, map[envelopes.ID]string) {
+	ids := make(map[string]envelopes.ID, len(entries))
+	names := make(map[envelopes.ID]string, len(entries))
+	for i, entry := range entries {
+		var id envelopes.ID
+		id[0] = byte(i + 1)
+		ids[entry[0]] = id
+		names[id] = entry[0]
+	}
+
+	start := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	nodes := make(map[envelopes.ID]Node, len(entries))
+	for i, entry := range entries {
+		node := Node{ID: ids[entry[0]], Time: start.Add(-time.Duration(i) * time.Hour)}
+		for _, parent := range entry[1:] {
+			node.Parents = append(node.Parents, ids[parent])
+		}
+		nodes[node.ID] = node
+	}
+	return nodes, names
+}
+
+func TestGraph_Write(t *testing.T) {
+	nodes, names := history(
+		[]string{"after", "merge"},
+		[]string{"merge", "ours", "theirs2"},
+		[]string{"ours", "base"},
+		[]string{"theirs2", "theirs1"},
+		[]string{"theirs1", "base"},
+		[]string{"base"},
+	)
+
+	output := &bytes.Buffer{}
+	drawing := &Graph{}
+	for _, node := range Sort(nodes) {
+		err := drawing.Write(output, node, names[node.ID]+"\n")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	const expected = `* after
+* merge
+|\
+* | ours
+| * theirs2
+| * theirs1
+|/
+* base
+`
+
+	if got := output.String(); got != expected {
+		t.Logf("\ngot:\n%s\nwant:\n%s", got, expected)
+		t.Fail()
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	nodes, names := history(
+		[]string{"merge", "ours", "theirs"},
+		[]string{"ours", "base"},
+		[]string{"theirs", "base"},
+		[]string{"base", "root"},
+		[]string{"root"},
+	)
+
+	simplified := Simplify(nodes, func(id envelopes.ID) bool {
+		return names[id] != "ours" && names[id] != "base"
+	})
+
+	if len(simplified) != 3 {
+		t.Logf("got %d nodes, want 3", len(simplified))
+		t.Fail()
+	}
+
+	for _, node := range simplified {
+		var parents []string
+		for _, parent := range node.Parents {
+			parents = append(parents, names[parent])
+		}
+
+		var expected []string
+		switch names[node.ID] {
+		case "merge":
+			expected = []string{"root", "theirs"}
+		case "theirs":
+			expected = []string{"root"}
+		}
+
+		if len(parents) != len(expected) {
+			t.Logf("%s has parents %v, want %v", names[node.ID], parents, expected)
+			t.Fail()
+			continue
+		}
+		for i := range parents {
+			if parents[i] != expected[i] {
+				t.Logf("%s has parents %v, want %v", names[node.ID], parents, expected)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
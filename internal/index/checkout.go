@@ -17,44 +17,93 @@ package index
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/marstr/envelopes"
 )
 
+const (
+	// stagingName is the directory, inside of RepoName, where a checkout is assembled before it replaces the index.
+	stagingName = "checkout"
+
+	// stagedNewName holds the accounts and budget that are being checked out.
+	stagedNewName = "new"
+
+	// stagedOldName holds the accounts and budget that were replaced, until the checkout has finished.
+	stagedOldName = "old"
+
+	// stagedReadyName is written once everything being checked out has been staged. Its presence means an interrupted
+	// checkout should be finished, rather than discarded.
+	stagedReadyName = "ready"
+)
+
 // CheckoutState offers a shortcut to calling Checkout, should you know that the ID that has been
 // handed to you points to an envelopes.State.
+//
+// The new accounts and budget are written to a staging area, then renamed into place. Should a checkout be
+// interrupted, the index is returned to either the old state or the new one the next time a State is checked out, or
+// RecoverCheckout is called. Like RecoverCheckout, it should only be called while holding the repository's lock.
 func CheckoutState(ctx context.Context, state *envelopes.State, targetDir string, perm os.FileMode) error {
 	targetDir, err := RootDirectory(targetDir)
 	if err != nil {
 		return err
 	}
 
-	// Delete all existing contents to prevent inadvertent merge.
+	// Settle any checkout that was interrupted, so that its leftovers aren't mistaken for this one's.
+	err = RecoverCheckout(targetDir)
+	if err != nil {
+		return err
+	}
+
+	staging := filepath.Join(targetDir, RepoName, stagingName)
+	err = stageState(ctx, state, targetDir, filepath.Join(staging, stagedNewName), perm)
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(staging, stagedReadyName), nil, perm)
+	}
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	// From here on, the checkout is no longer allowed to be cancelled. Everything it needs is on disk, so the only
+	// thing left to do is move it into place.
+	return RecoverCheckout(targetDir)
+}
 
-	accountsDir := path.Join(targetDir, AccountsDir)
+// stageState writes the accounts and budget of a State into an empty directory, carrying over the goals that are set
+// on budgets in the index rooted at root. Goals whose budgets aren't in the State are staged to be stashed in RepoName.
+func stageState(ctx context.Context, state *envelopes.State, root string, stagingDir string, perm os.FileMode) error {
+	accountsDir := filepath.Join(stagingDir, AccountsDir)
 
-	err = os.RemoveAll(accountsDir)
-	if err != nil && !os.IsNotExist(err) {
+	err := os.MkdirAll(accountsDir, perm|os.ModeDir|0110)
+	if err != nil {
 		return err
 	}
 
-	err = os.Mkdir(accountsDir, perm|os.ModeDir|0110)
+	budgetDir := filepath.Join(stagingDir, BudgetDir)
+
+	err = os.Mkdir(budgetDir, perm|os.ModeDir|0110)
 	if err != nil {
 		return err
 	}
 
-	budgetDir := path.Join(targetDir, BudgetDir)
-	err = os.RemoveAll(budgetDir)
+	// Goals aren't part of an envelopes.State, so carry them over from the budgets that are being replaced, along with
+	// any that were set aside because an earlier checkout removed their budget.
+	goals, err := loadStashedGoals(root)
 	if err != nil {
 		return err
 	}
 
-	err = os.Mkdir(budgetDir, perm|os.ModeDir|0110)
+	current, err := stashGoals(ctx, filepath.Join(root, BudgetDir))
 	if err != nil {
 		return err
 	}
+	for name, contents := range current {
+		goals[name] = contents
+	}
 
 	// Write accounts
 
@@ -77,7 +126,7 @@ func CheckoutState(ctx context.Context, state *envelopes.State, targetDir string
 	var processBudget func(context.Context, string, *envelopes.Budget) error
 
 	processBudget = func(ctx context.Context, location string, budget *envelopes.Budget) error {
-		err = os.MkdirAll(location, perm|os.ModeDir|0110)
+		err := os.MkdirAll(location, perm|os.ModeDir|0110)
 		if err != nil {
 			return err
 		}
@@ -104,9 +153,92 @@ func CheckoutState(ctx context.Context, state *envelopes.State, targetDir string
 		return nil
 	}
 	if state.Budget != nil {
-		return processBudget(ctx, budgetDir, state.Budget)
+		err = processBudget(ctx, budgetDir, state.Budget)
+		if err != nil {
+			return err
+		}
+	}
+
+	leftover, err := restoreGoals(ctx, budgetDir, goals, perm)
+	if err != nil {
+		return err
+	}
+
+	// The goals that no longer have a budget are always staged, even when there are none, so that RecoverCheckout can
+	// tell that an absent file has already been moved into place.
+	marshaled, err := json.MarshalIndent(leftover, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stagingDir, stashedGoalsName), marshaled, perm)
+}
+
+// CheckoutInterrupted determines whether a checkout was fully staged in the index rooted at root, but not finished
+// being moved into place. Until RecoverCheckout is called, the index may be a mix of the old and new balances.
+func CheckoutInterrupted(root string) (bool, error) {
+	_, err := os.Stat(filepath.Join(root, RepoName, stagingName, stagedReadyName))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RecoverCheckout settles a checkout that was staged in the index rooted at root. If the checkout was fully staged,
+// the replaced accounts and budget are moved out of the way and the staged ones are moved into place. Otherwise, the
+// index was never touched, and whatever was staged is discarded. Either way, the staging area is removed.
+//
+// Because it moves and removes files in the index, RecoverCheckout must only be called while holding the lock on the
+// repository.
+func RecoverCheckout(root string) error {
+	staging := filepath.Join(root, RepoName, stagingName)
+
+	_, err := os.Stat(filepath.Join(staging, stagedReadyName))
+	if os.IsNotExist(err) {
+		return os.RemoveAll(staging)
+	} else if err != nil {
+		return err
+	}
+
+	oldDir := filepath.Join(staging, stagedOldName)
+	err = os.MkdirAll(oldDir, os.ModePerm)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	for _, name := range []string{AccountsDir, BudgetDir} {
+		staged := filepath.Join(staging, stagedNewName, name)
+		if _, err = os.Stat(staged); os.IsNotExist(err) {
+			// This directory was already moved into place before the checkout was interrupted.
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		current := filepath.Join(root, name)
+		err = os.Rename(current, filepath.Join(oldDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		err = os.Rename(staged, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	staged := filepath.Join(staging, stagedNewName, stashedGoalsName)
+	if _, err = os.Stat(staged); err == nil {
+		err = os.Rename(staged, filepath.Join(root, RepoName, stashedGoalsName))
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.RemoveAll(staging)
 }
 
 // CheckoutTransaction offers a shortcut to calling Checkout, should you know that the ID that has
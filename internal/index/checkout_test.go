@@ -83,3 +83,285 @@ func TestCheckoutState_roundtrip(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckoutState_preservesGoals(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLocation, err := ioutil.TempDir("", "baronial_index_checkout_goals_")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(repoLocation)
+
+	err = os.Mkdir(path.Join(repoLocation, RepoName), os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	state := &envelopes.State{
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"rent":     {Balance: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+				"vacation": {Balance: envelopes.Balance{"USD": big.NewRat(100, 1)}},
+			},
+		},
+	}
+
+	err = CheckoutState(ctx, state, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	const rentGoal = "monthly USD 1000\n"
+	err = ioutil.WriteFile(path.Join(repoLocation, BudgetDir, "rent", goalName), []byte(rentGoal), os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	delete(state.Budget.Children, "vacation")
+	err = CheckoutState(ctx, state, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	contents, err := ioutil.ReadFile(path.Join(repoLocation, BudgetDir, "rent", goalName))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got := string(contents); got != rentGoal {
+		t.Logf("goal was not preserved\n\tgot:  %q\n\twant: %q", got, rentGoal)
+		t.Fail()
+	}
+}
+
+func TestCheckoutState_stashesRemovedGoals(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLocation := t.TempDir()
+	err := os.Mkdir(path.Join(repoLocation, RepoName), os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	withVacation := &envelopes.State{
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"rent": {Balance: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+				"vacation": {
+					Children: map[string]*envelopes.Budget{
+						"flights": {Balance: envelopes.Balance{"USD": big.NewRat(100, 1)}},
+					},
+				},
+			},
+		},
+	}
+	withoutVacation := &envelopes.State{
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"rent": {Balance: envelopes.Balance{"USD": big.NewRat(500, 1)}},
+			},
+		},
+	}
+
+	err = CheckoutState(ctx, withVacation, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	const flightsGoal = "target USD 1200 by 2026-12-01\n"
+	flightsGoalLocation := path.Join(repoLocation, BudgetDir, "vacation", "flights", goalName)
+	err = ioutil.WriteFile(flightsGoalLocation, []byte(flightsGoal), os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = CheckoutState(ctx, withoutVacation, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = os.Stat(path.Join(repoLocation, BudgetDir, "vacation")); !os.IsNotExist(err) {
+		t.Logf("expected the removed budget to be gone, got: %v", err)
+		t.Fail()
+	}
+
+	// Checking out a State without the budget again must not forget its goal.
+	err = CheckoutState(ctx, withoutVacation, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = CheckoutState(ctx, withVacation, repoLocation, os.ModePerm)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	contents, err := ioutil.ReadFile(flightsGoalLocation)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got := string(contents); got != flightsGoal {
+		t.Logf("goal was not restored\n\tgot:  %q\n\twant: %q", got, flightsGoal)
+		t.Fail()
+	}
+
+	stashed, err := loadStashedGoals(repoLocation)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(stashed) != 0 {
+		t.Logf("expected no goals to remain stashed, got: %v", stashed)
+		t.Fail()
+	}
+}
+
+func TestCheckoutState_interrupted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	before := &envelopes.State{
+		Accounts: map[string]envelopes.Balance{"checking": {"USD": big.NewRat(100, 1)}},
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"rent": {Balance: envelopes.Balance{"USD": big.NewRat(100, 1)}},
+			},
+		},
+	}
+	after := &envelopes.State{
+		Accounts: map[string]envelopes.Balance{"checking": {"USD": big.NewRat(60, 1)}},
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"rent":      {Balance: envelopes.Balance{"USD": big.NewRat(40, 1)}},
+				"groceries": {Balance: envelopes.Balance{"USD": big.NewRat(20, 1)}},
+			},
+		},
+	}
+
+	setup := func(t *testing.T) string {
+		repoLocation := t.TempDir()
+		err := os.Mkdir(path.Join(repoLocation, RepoName), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = CheckoutState(ctx, before, repoLocation, os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repoLocation
+	}
+
+	expect := func(t *testing.T, repoLocation string, want *envelopes.State) {
+		err := RecoverCheckout(repoLocation)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		got, err := LoadState(ctx, repoLocation)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !got.Equal(*want) {
+			t.Logf("\n\tgot:  %s\n\twant: %s", got, want)
+			t.Fail()
+		}
+
+		if _, err = os.Stat(path.Join(repoLocation, RepoName, stagingName)); !os.IsNotExist(err) {
+			t.Logf("expected the staging area to have been cleaned up, got: %v", err)
+			t.Fail()
+		}
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		repoLocation := setup(t)
+
+		cancelled, cancelNow := context.WithCancel(ctx)
+		cancelNow()
+
+		if err := CheckoutState(cancelled, after, repoLocation, os.ModePerm); err == nil {
+			t.Log("expected a cancelled checkout to fail")
+			t.Fail()
+		}
+
+		expect(t, repoLocation, before)
+	})
+
+	t.Run("staged", func(t *testing.T) {
+		repoLocation := setup(t)
+		staging := path.Join(repoLocation, RepoName, stagingName)
+
+		err := stageState(ctx, after, repoLocation, path.Join(staging, stagedNewName), os.ModePerm)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		expect(t, repoLocation, before)
+	})
+
+	t.Run("halfway", func(t *testing.T) {
+		repoLocation := setup(t)
+		staging := path.Join(repoLocation, RepoName, stagingName)
+
+		err := stageState(ctx, after, repoLocation, path.Join(staging, stagedNewName), os.ModePerm)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = ioutil.WriteFile(path.Join(staging, stagedReadyName), nil, os.ModePerm)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Simulate being interrupted after the old accounts were moved aside, but before the new ones were moved in.
+		err = os.MkdirAll(path.Join(staging, stagedOldName), os.ModePerm)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = os.Rename(path.Join(repoLocation, AccountsDir), path.Join(staging, stagedOldName, AccountsDir))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if interrupted, err := CheckoutInterrupted(repoLocation); err != nil || !interrupted {
+			t.Logf("expected the checkout to be reported as interrupted, got: %v (error: %v)", interrupted, err)
+			t.Fail()
+		}
+
+		// Reading the index must not try to finish the checkout, because readers don't hold the lock.
+		_, _ = LoadState(ctx, repoLocation)
+		if _, err = os.Stat(path.Join(staging, stagedReadyName)); err != nil {
+			t.Logf("expected loading the index to leave the staging area alone, got: %v", err)
+			t.Fail()
+		}
+
+		expect(t, repoLocation, after)
+	})
+}
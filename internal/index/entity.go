@@ -0,0 +1,80 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package index
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/marstr/envelopes"
+)
+
+// AdjustState adds delta to the balance of an account or budget in a State. Entities are named the same way they are
+// in the index, for example "accounts/checking" or "budget/grocery". Budgets that don't exist yet are created.
+func AdjustState(state *envelopes.State, entity string, delta envelopes.Balance) error {
+	dir, name := SplitEntityName(entity)
+
+	switch dir {
+	case AccountsDir:
+		if name == "" {
+			return fmt.Errorf("%q does not name an account", entity)
+		}
+		if state.Accounts == nil {
+			state.Accounts = envelopes.Accounts{}
+		}
+		state.Accounts[name] = state.Accounts[name].Add(delta)
+	case BudgetDir:
+		if state.Budget == nil {
+			state.Budget = &envelopes.Budget{}
+		}
+		target := state.Budget
+		if name != "" {
+			for _, segment := range strings.Split(name, "/") {
+				if target.Children == nil {
+					target.Children = make(map[string]*envelopes.Budget)
+				}
+				child, ok := target.Children[segment]
+				if !ok {
+					child = &envelopes.Budget{}
+					target.Children[segment] = child
+				}
+				target = child
+			}
+		}
+		target.Balance = target.Balance.Add(delta)
+	default:
+		return fmt.Errorf("%q was recognized as neither a budget nor an account", entity)
+	}
+
+	return nil
+}
+
+// SplitEntityName separates the name of an account or budget, as it would be written relative to the root of the
+// index, into the directory it lives in and the name it is stored under in an envelopes.State.
+func SplitEntityName(entity string) (dir string, name string) {
+	entity = path.Clean(filepath.ToSlash(entity))
+	entity = strings.TrimPrefix(entity, "/")
+
+	dir, name, _ = strings.Cut(entity, "/")
+	if dir != AccountsDir && dir != BudgetDir {
+		return "", ""
+	}
+	return dir, name
+}
@@ -0,0 +1,28 @@
+package index
+
+import "testing"
+
+func TestSplitEntityName(t *testing.T) {
+	testCases := []struct {
+		entity string
+		dir    string
+		name   string
+	}{
+		{"accounts/checking", AccountsDir, "checking"},
+		{"./accounts/checking/", AccountsDir, "checking"},
+		{"/budget/food/groceries", BudgetDir, "food/groceries"},
+		{"budget", BudgetDir, ""},
+		{"budget/.hidden", BudgetDir, ".hidden"},
+		{"../accounts/checking", "", ""},
+		{".accounts/checking", "", ""},
+		{"savings", "", ""},
+	}
+
+	for _, tc := range testCases {
+		dir, name := SplitEntityName(tc.entity)
+		if dir != tc.dir || name != tc.name {
+			t.Logf("%q\n\tgot:  %q %q\n\twant: %q %q", tc.entity, dir, name, tc.dir, tc.name)
+			t.Fail()
+		}
+	}
+}
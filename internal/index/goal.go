@@ -0,0 +1,328 @@
+// Copyright © 2026 Martin Strobel
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/spf13/cast"
+)
+
+const (
+	goalName    = "goal.txt"
+	goalFileMax = int64(2048)
+
+	// stashedGoalsName is the file, inside of RepoName, that holds the goals of budgets which were removed by a
+	// checkout. They are put back should a later checkout bring the budget back.
+	stashedGoalsName = "stashed_goals.json"
+)
+
+// GoalKind identifies the way a Goal decides how much a budget should be funded.
+type GoalKind string
+
+// These are the kinds of Goal that can be written in a budget's goal file.
+const (
+	// GoalMonthly refills a budget up to a fixed balance each time it is funded.
+	GoalMonthly GoalKind = "monthly"
+
+	// GoalTarget saves towards a balance by a given date, spreading the remainder evenly over the months left.
+	GoalTarget GoalKind = "target"
+
+	// GoalPercent sets aside a share of each paycheck or other income.
+	GoalPercent GoalKind = "percent"
+)
+
+// Goal describes how much money a budget should have. Goals are read from a "goal.txt" file that sits next to a
+// budget's "cash.txt", and contain a single line in one of these forms:
+//
+//	monthly USD 200
+//	target USD 1200 by 2026-12-01
+//	percent 10
+type Goal struct {
+	Kind    GoalKind
+	Amount  envelopes.Balance
+	By      time.Time
+	Percent *big.Rat
+}
+
+// ErrBadGoal is returned when the contents of a goal file can't be understood.
+type ErrBadGoal string
+
+func (e ErrBadGoal) Error() string {
+	return fmt.Sprintf("%q is not a recognized goal (expected \"monthly {amount}\", \"target {amount} by {date}\", or \"percent {n}\")", string(e))
+}
+
+// ParseGoal interprets the text of a goal file.
+func ParseGoal(raw string) (Goal, error) {
+	trimmed := strings.TrimSpace(raw)
+	fields := strings.Fields(trimmed)
+	if len(fields) < 2 {
+		return Goal{}, ErrBadGoal(trimmed)
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(trimmed, fields[0]))
+
+	switch kind := GoalKind(strings.ToLower(fields[0])); kind {
+	case GoalMonthly:
+		amount, err := envelopes.ParseBalance([]byte(rest))
+		if err != nil {
+			return Goal{}, ErrBadGoal(trimmed)
+		}
+		return Goal{Kind: kind, Amount: amount}, nil
+	case GoalTarget:
+		separator := strings.LastIndex(strings.ToLower(rest), " by ")
+		if separator < 0 {
+			return Goal{}, ErrBadGoal(trimmed)
+		}
+
+		amount, err := envelopes.ParseBalance([]byte(strings.TrimSpace(rest[:separator])))
+		if err != nil {
+			return Goal{}, ErrBadGoal(trimmed)
+		}
+
+		by, err := cast.ToTimeE(strings.TrimSpace(rest[separator+len(" by "):]))
+		if err != nil {
+			return Goal{}, ErrBadGoal(trimmed)
+		}
+		return Goal{Kind: kind, Amount: amount, By: by}, nil
+	case GoalPercent:
+		percent, ok := new(big.Rat).SetString(strings.TrimSuffix(rest, "%"))
+		if !ok || percent.Sign() <= 0 {
+			return Goal{}, ErrBadGoal(trimmed)
+		}
+		return Goal{Kind: kind, Percent: percent}, nil
+	default:
+		return Goal{}, ErrBadGoal(trimmed)
+	}
+}
+
+func (g Goal) String() string {
+	switch g.Kind {
+	case GoalMonthly:
+		return fmt.Sprintf("%s %s", g.Kind, g.Amount)
+	case GoalTarget:
+		return fmt.Sprintf("%s %s by %s", g.Kind, g.Amount, g.By.Format("2006-01-02"))
+	case GoalPercent:
+		return fmt.Sprintf("%s %s", g.Kind, strings.TrimRight(strings.TrimRight(g.Percent.FloatString(3), "0"), "."))
+	default:
+		return string(g.Kind)
+	}
+}
+
+// Needed calculates how much should be added to a budget with the given balance to satisfy this Goal as of a point in
+// time. Percentage goals are calculated against income, and ask for nothing when there is no income. Goals never ask
+// for a budget to give money up, and amounts are rounded up to the nearest hundredth.
+func (g Goal) Needed(current envelopes.Balance, now time.Time, income envelopes.Balance) envelopes.Balance {
+	var needed envelopes.Balance
+	switch g.Kind {
+	case GoalMonthly:
+		needed = g.Amount.Sub(current)
+	case GoalTarget:
+		needed = g.Amount.Sub(current)
+		if months := monthsUntil(now, g.By); months > 1 {
+			divisor := big.NewRat(int64(months), 1)
+			for asset, magnitude := range needed {
+				needed[asset] = magnitude.Quo(magnitude, divisor)
+			}
+		}
+	case GoalPercent:
+		needed = make(envelopes.Balance, len(income))
+		share := new(big.Rat).Quo(g.Percent, big.NewRat(100, 1))
+		for asset, magnitude := range income {
+			needed[asset] = new(big.Rat).Mul(magnitude, share)
+		}
+	}
+
+	retval := make(envelopes.Balance, len(needed))
+	for asset, magnitude := range needed {
+		if magnitude.Sign() <= 0 {
+			continue
+		}
+		retval[asset] = roundUpToHundredths(magnitude)
+	}
+	return retval
+}
+
+// monthsUntil counts the calendar months from now up to and including the month of a deadline.
+func monthsUntil(now, deadline time.Time) int {
+	return (deadline.Year()-now.Year())*12 + int(deadline.Month()) - int(now.Month()) + 1
+}
+
+func roundUpToHundredths(subject *big.Rat) *big.Rat {
+	scaled := new(big.Rat).Mul(subject, big.NewRat(100, 1))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return new(big.Rat).SetFrac(quotient, big.NewInt(100))
+}
+
+// LoadGoal reads the Goal of the budget in the given directory. If the budget doesn't have a goal, the error
+// satisfies os.IsNotExist.
+func LoadGoal(_ context.Context, dirname string) (Goal, error) {
+	handle, err := os.Open(filepath.Join(dirname, goalName))
+	if err != nil {
+		return Goal{}, err
+	}
+	defer handle.Close()
+
+	contents, err := ioutil.ReadAll(io.LimitReader(handle, goalFileMax))
+	if err != nil {
+		return Goal{}, err
+	}
+
+	return ParseGoal(string(contents))
+}
+
+// LoadGoals finds every Goal in a budget directory and its children. Goals are keyed by the name of their budget,
+// relative to the directory that was searched.
+func LoadGoals(ctx context.Context, dirname string) (map[string]Goal, error) {
+	retval := make(map[string]Goal)
+
+	err := filepath.Walk(dirname, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if current != dirname && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		goal, err := LoadGoal(ctx, current)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("couldn't read the goal for %q: %w", current, err)
+		}
+
+		name, err := filepath.Rel(dirname, current)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			name = ""
+		}
+		retval[filepath.ToSlash(name)] = goal
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retval, nil
+}
+
+// stashGoals reads the raw contents of every goal file in a budget directory, keyed by the slash separated name of
+// the directory that holds it relative to dirname.
+func stashGoals(ctx context.Context, dirname string) (map[string]string, error) {
+	retval := make(map[string]string)
+
+	err := filepath.Walk(dirname, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		if info.IsDir() || info.Name() != goalName {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(current)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dirname, filepath.Dir(current))
+		if err != nil {
+			return err
+		}
+		retval[filepath.ToSlash(rel)] = string(contents)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return retval, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return retval, nil
+}
+
+// loadStashedGoals reads the goals that were set aside by an earlier checkout, because their budgets were removed.
+func loadStashedGoals(root string) (map[string]string, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(root, RepoName, stashedGoalsName))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var retval map[string]string
+	err = json.Unmarshal(contents, &retval)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the stashed goals: %w", err)
+	}
+	if retval == nil {
+		retval = map[string]string{}
+	}
+	return retval, nil
+}
+
+// restoreGoals writes goal files that were read by stashGoals back into a budget directory. Goals that belong to
+// budgets which no longer exist are returned, so that they can be kept until the budget comes back.
+func restoreGoals(_ context.Context, dirname string, goals map[string]string, perm os.FileMode) (map[string]string, error) {
+	leftover := make(map[string]string)
+	for rel, contents := range goals {
+		location := filepath.Join(dirname, filepath.FromSlash(rel))
+		if info, err := os.Stat(location); err != nil || !info.IsDir() {
+			leftover[rel] = contents
+			continue
+		}
+
+		err := ioutil.WriteFile(filepath.Join(location, goalName), []byte(contents), perm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return leftover, nil
+}
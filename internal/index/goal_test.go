@@ -0,0 +1,77 @@
+package index
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestParseGoal(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected string
+	}{
+		{"monthly USD 200\n", "monthly USD 200.000"},
+		{"Monthly USD 25.50", "monthly USD 25.500"},
+		{"target USD 1200 by 2026-12-01", "target USD 1200.000 by 2026-12-01"},
+		{"percent 10", "percent 10"},
+		{"percent 12.5%", "percent 12.5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			got, err := ParseGoal(tc.raw)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if got.String() != tc.expected {
+				t.Logf("\n\tgot:  %q\n\twant: %q", got.String(), tc.expected)
+				t.Fail()
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "monthly", "weekly USD 20", "target USD 100", "percent -4", "percent lots"} {
+		if _, err := ParseGoal(bad); err == nil {
+			t.Logf("expected an error for %q", bad)
+			t.Fail()
+		}
+	}
+}
+
+func TestGoal_Needed(t *testing.T) {
+	now := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	income := envelopes.Balance{"USD": big.NewRat(2000, 1)}
+
+	testCases := []struct {
+		goal     string
+		current  envelopes.Balance
+		expected envelopes.Balance
+	}{
+		{"monthly USD 200", envelopes.Balance{"USD": big.NewRat(50, 1)}, envelopes.Balance{"USD": big.NewRat(150, 1)}},
+		{"monthly USD 200", envelopes.Balance{"USD": big.NewRat(250, 1)}, envelopes.Balance{}},
+		{"target USD 1000 by 2026-12-15", envelopes.Balance{"USD": big.NewRat(100, 1)}, envelopes.Balance{"USD": big.NewRat(300, 1)}},
+		{"target USD 100 by 2026-12-15", envelopes.Balance{}, envelopes.Balance{"USD": big.NewRat(3334, 100)}},
+		{"target USD 100 by 2026-01-01", envelopes.Balance{"USD": big.NewRat(40, 1)}, envelopes.Balance{"USD": big.NewRat(60, 1)}},
+		{"percent 10", envelopes.Balance{"USD": big.NewRat(5000, 1)}, envelopes.Balance{"USD": big.NewRat(200, 1)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.goal, func(t *testing.T) {
+			goal, err := ParseGoal(tc.goal)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if got := goal.Needed(tc.current, now, income); !got.Equal(tc.expected) {
+				t.Logf("current %v\n\tgot:  %v\n\twant: %v", tc.current, got, tc.expected)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/marstr/envelopes"
 	"github.com/marstr/units/data"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -33,7 +34,8 @@ const (
 	cashFileMax = int64(2 * data.Kilobyte)
 )
 
-// LoadState hydrates both accounts and the budget from the repository in the folder presented.
+// LoadState hydrates both accounts and the budget from the repository in the folder presented. LoadState only reads,
+// so a checkout that was interrupted is left for RecoverCheckout, and the index may be read halfway through it.
 func LoadState(ctx context.Context, dirname string) (*envelopes.State, error) {
 	var retval envelopes.State
 	var err error
@@ -43,6 +45,12 @@ func LoadState(ctx context.Context, dirname string) (*envelopes.State, error) {
 		return nil, err
 	}
 
+	if interrupted, err := CheckoutInterrupted(dirname); err != nil {
+		return nil, err
+	} else if interrupted {
+		logrus.Warn("a checkout was interrupted, so balances may be out of date until a command that changes the repository finishes it")
+	}
+
 	retval.Accounts, err = LoadAccounts(ctx, path.Join(dirname, AccountsDir))
 	if err != nil {
 		return nil, err
@@ -94,9 +102,6 @@ func LoadAccounts(ctx context.Context, dirname string) (envelopes.Accounts, erro
 			} else if e.Name() == cashName {
 				// If we've found a cash balance file in the accounts directory of a baronial repository, we've found an
 				// account.
-				var reader io.Reader
-				var handle *os.File
-				var contents []byte
 				var bal envelopes.Balance
 
 				// Determine the account name. If this is not an account, there is no need to continue.
@@ -108,21 +113,7 @@ func LoadAccounts(ctx context.Context, dirname string) (envelopes.Accounts, erro
 				}
 
 				// Read the contents of the account
-				handle, err = os.Open(fullEntryName)
-				if err != nil {
-					return envelopes.Accounts{}, err
-				}
-				reader = io.LimitReader(handle, cashFileMax)
-
-				contents, err = ioutil.ReadAll(reader)
-				handle.Close()
-				if err != nil {
-					return envelopes.Accounts{}, err
-				}
-
-				trimmed := strings.TrimSpace(string(contents))
-
-				bal, err = envelopes.ParseBalance([]byte(trimmed))
+				bal, err = readAccountBalance(fullEntryName)
 				if err != nil {
 					return envelopes.Accounts{}, err
 				}
@@ -137,6 +128,48 @@ func LoadAccounts(ctx context.Context, dirname string) (envelopes.Accounts, erro
 	return helper(ctx, dirname, make(envelopes.Accounts, 0))
 }
 
+// LoadAccount reads the balance of a single account from the current baronial index. An account directory that
+// doesn't have a balance yet is treated as being empty.
+func LoadAccount(ctx context.Context, dirname string) (envelopes.Balance, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// Intentionally Left Blank
+	}
+
+	name, err := AccountName(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, ErrNotAccount(dirname)
+	}
+
+	bal, err := readAccountBalance(filepath.Join(dirname, cashName))
+	if os.IsNotExist(err) {
+		return envelopes.Balance{}, nil
+	}
+	return bal, err
+}
+
+func readAccountBalance(filename string) (envelopes.Balance, error) {
+	handle, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	contents, err := ioutil.ReadAll(io.LimitReader(handle, cashFileMax))
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := strings.TrimSpace(string(contents))
+	return envelopes.ParseBalance([]byte(trimmed))
+}
+
 // LoadBudget reads the budget portion of the current baronial index into memory.
 func LoadBudget(ctx context.Context, dirname string) (retval *envelopes.Budget, err error) {
 	var entries []os.FileInfo
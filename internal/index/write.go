@@ -16,6 +16,7 @@
 package index
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -26,20 +27,76 @@ import (
 	"github.com/marstr/envelopes"
 )
 
+// cashFilePermissions allows the owner and group to read and write balances. The files aren't executable, and other
+// users can't read them.
+const cashFilePermissions = 0660
+
 // WriteBudget takes the memoized Budget and commits it to the current baronial index.
 func WriteBudget(ctx context.Context, targetDir string, budget envelopes.Budget) error {
 	if len(budget.Balance) == 0 {
 		return nil
 	}
 
-	targetFile := filepath.Join(targetDir, cashName)
-	handle, err := os.Create(targetFile)
+	var buf bytes.Buffer
+	err := writeBalance(ctx, &buf, budget.Balance)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(targetDir, cashName), buf.Bytes(), cashFilePermissions)
+}
+
+// WriteAccount commits the balance of a single account to the current baronial index. Unlike budgets, an account is
+// always written, even when it is empty, because the presence of its balance file is what makes it an account.
+func WriteAccount(ctx context.Context, targetDir string, balance envelopes.Balance) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// Intentionally Left Blank
+	}
+
+	name, err := AccountName(targetDir)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		return ErrNotAccount(targetDir)
+	}
+
+	return writeFileAtomic(filepath.Join(targetDir, cashName), []byte(balance.String()), cashFilePermissions)
+}
+
+// writeFileAtomic replaces the contents of a file by writing them to a temporary file in the same directory, then
+// renaming it into place. Should writing be interrupted, the file is left with either its old contents or its new
+// ones, never a mix of the two.
+func writeFileAtomic(filename string, contents []byte, perm os.FileMode) error {
+	handle, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	defer handle.Close()
+	tempName := handle.Name()
 
-	return writeBalance(ctx, handle, budget.Balance)
+	_, err = handle.Write(contents)
+	if err == nil {
+		err = handle.Sync()
+	}
+	if closeErr := handle.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempName, filename)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	return nil
 }
 
 func writeBalance(_ context.Context, output io.Writer, bal envelopes.Balance) error {
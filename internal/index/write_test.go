@@ -0,0 +1,78 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestWriteAccount_roundtrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	repoLocation := t.TempDir()
+	checking := filepath.Join(repoLocation, AccountsDir, "bank", "checking")
+	for _, dir := range []string{filepath.Join(repoLocation, RepoName), checking, filepath.Join(repoLocation, BudgetDir)} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	got, err := LoadAccount(ctx, checking)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(got) != 0 {
+		t.Logf("expected a new account to be empty, got: %s", got)
+		t.Fail()
+	}
+
+	testCases := []envelopes.Balance{
+		{"USD": big.NewRat(10096, 100)},
+		{"USD": big.NewRat(-2550, 100), "EUR": big.NewRat(7, 1)},
+		{},
+	}
+
+	for _, tc := range testCases {
+		err = WriteAccount(ctx, checking, tc)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		got, err = LoadAccount(ctx, checking)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !got.Equal(tc) {
+			t.Logf("\n\tgot:  %s\n\twant: %s", got, tc)
+			t.Fail()
+		}
+
+		accounts, err := LoadAccounts(ctx, filepath.Join(repoLocation, AccountsDir))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if _, ok := accounts["bank/checking"]; !ok {
+			t.Logf("expected the written account to be loaded with the rest, got: %v", accounts)
+			t.Fail()
+		}
+	}
+
+	err = WriteAccount(ctx, filepath.Join(repoLocation, BudgetDir), envelopes.Balance{"USD": big.NewRat(1, 1)})
+	if !errors.As(err, new(ErrNotAccount)) {
+		t.Logf("expected writing an account into the budget to fail, got: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package lock keeps more than one baronial process from changing a repository at the same time. The lock is
+// advisory: it only protects a repository from processes that also take it.
+package lock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// Filename is the name of the file, inside the repository's metadata directory, that shows the lock is held.
+	Filename = "lock.json"
+
+	// unreadableGrace is how long a lock file that can't be understood is respected. It covers the moment between a
+	// process creating the file and finishing writing its details into it.
+	unreadableGrace = 10 * time.Second
+
+	// pollInterval is how often Acquire checks whether a lock has been released.
+	pollInterval = 100 * time.Millisecond
+)
+
+// owner describes the process holding a lock.
+type owner struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// ErrLocked is returned when another process is holding the lock on a repository.
+type ErrLocked struct {
+	Location string
+	PID      int
+	Hostname string
+	Acquired time.Time
+}
+
+func (e ErrLocked) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("the repository is locked by another baronial process (see %q)", e.Location)
+	}
+	return fmt.Sprintf("the repository is locked by another baronial process (pid %d on %q, since %s)", e.PID, e.Hostname, e.Acquired.Format(time.RFC3339))
+}
+
+// Lock is held by this process on a repository, until it is released.
+type Lock struct {
+	location string
+	contents []byte
+}
+
+// TryAcquire takes the lock on the repository whose metadata is stored in repoLoc. If another process is holding it,
+// ErrLocked is returned immediately. Locks left behind by processes that are no longer running are cleared away.
+func TryAcquire(repoLoc string) (*Lock, error) {
+	location := filepath.Join(repoLoc, Filename)
+
+	hostname, _ := os.Hostname()
+	contents, err := json.Marshal(owner{
+		PID:      os.Getpid(),
+		Hostname: hostname,
+		Acquired: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var handle *os.File
+		handle, err = os.OpenFile(location, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0660)
+		if err == nil {
+			_, err = handle.Write(contents)
+			if closeErr := handle.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(location)
+				return nil, err
+			}
+			return &Lock{location: location, contents: contents}, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+
+		var existing []byte
+		existing, err = os.ReadFile(location)
+		if os.IsNotExist(err) {
+			// It was released while we were looking at it, try again.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		held, stale := inspect(location, existing, hostname)
+		if !stale {
+			return nil, held
+		}
+
+		err = removeIfUnchanged(location, existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Acquire takes the lock on the repository whose metadata is stored in repoLoc, waiting for other processes to release
+// it for as long as ctx allows.
+func Acquire(ctx context.Context, repoLoc string) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := TryAcquire(repoLoc)
+		if _, ok := err.(ErrLocked); !ok {
+			return acquired, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up waiting: %w", err)
+		case <-ticker.C:
+			// Intentionally Left Blank
+		}
+	}
+}
+
+// Release gives up the lock, so that other processes may take it. Releasing a lock more than once has no effect, and
+// a lock that was cleared away and taken by another process is left alone.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	return removeIfUnchanged(l.location, l.contents)
+}
+
+// inspect determines who is holding a lock, and whether or not they can be assumed to have abandoned it. Only the
+// processes of this machine can be checked, so a lock that was taken elsewhere is never treated as stale.
+func inspect(location string, contents []byte, hostname string) (ErrLocked, bool) {
+	held := ErrLocked{Location: location}
+
+	var found owner
+	if err := json.Unmarshal(contents, &found); err != nil || found.PID == 0 {
+		info, err := os.Stat(location)
+		return held, err == nil && time.Since(info.ModTime()) > unreadableGrace
+	}
+
+	held.PID = found.PID
+	held.Hostname = found.Hostname
+	held.Acquired = found.Acquired
+
+	if found.Hostname != hostname || found.PID == os.Getpid() {
+		return held, false
+	}
+	return held, !processAlive(found.PID)
+}
+
+// removeIfUnchanged deletes a lock file, as long as it still has the contents that were expected.
+func removeIfUnchanged(location string, expected []byte) error {
+	current, err := os.ReadFile(location)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current, expected) {
+		return nil
+	}
+
+	err = os.Remove(location)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryAcquire(t *testing.T) {
+	repoLoc := t.TempDir()
+
+	first, err := TryAcquire(repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = TryAcquire(repoLoc); !errors.As(err, new(ErrLocked)) {
+		t.Logf("expected the lock to be held, got: %v", err)
+		t.Fail()
+	}
+
+	if err = first.Release(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Releasing twice shouldn't disturb a lock that somebody else has taken since.
+	second, err := TryAcquire(repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = first.Release(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = os.Stat(filepath.Join(repoLoc, Filename)); err != nil {
+		t.Logf("expected the second lock to still be held, got: %v", err)
+		t.Fail()
+	}
+
+	if err = second.Release(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTryAcquire_stale(t *testing.T) {
+	repoLoc := t.TempDir()
+
+	// Find a process ID that is guaranteed to not be running anymore.
+	finished := exec.Command(os.Args[0], "-test.run=^$")
+	if err := finished.Run(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	contents, err := json.Marshal(owner{PID: finished.ProcessState.Pid(), Hostname: hostname, Acquired: time.Now()})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = os.WriteFile(filepath.Join(repoLoc, Filename), contents, 0660)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	acquired, err := TryAcquire(repoLoc)
+	if err != nil {
+		t.Logf("expected the stale lock to be cleared away, got: %v", err)
+		t.Fail()
+		return
+	}
+
+	if err = acquired.Release(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	repoLoc := t.TempDir()
+
+	held, err := TryAcquire(repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*pollInterval)
+	_, err = Acquire(ctx, repoLoc)
+	cancel()
+	if !errors.As(err, new(ErrLocked)) {
+		t.Logf("expected to give up waiting for the lock, got: %v", err)
+		t.Fail()
+	}
+
+	go func() {
+		time.Sleep(3 * pollInterval)
+		_ = held.Release()
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	acquired, err := Acquire(ctx, repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = acquired.Release(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package merge combines the balances of branches that have diverged from a common ancestor, one account and one
+// budget at a time, and points out where the branches disagree.
+package merge
+
+import (
+	"path"
+	"sort"
+
+	"github.com/marstr/envelopes"
+
+	"github.com/marstr/baronial/internal/index"
+)
+
+// Conflict describes an account or budget whose balance was changed differently by more than one branch.
+type Conflict struct {
+	// Path names the account or budget relative to the root of the index, for example "accounts/checking" or
+	// "budget/groceries".
+	Path string `json:"path"`
+
+	// Base is the balance in the common ancestor of the branches, or nil if it didn't exist there.
+	Base envelopes.Balance `json:"base"`
+
+	// Sides are the balances on each branch, in the order they were merged. An entry is nil if the branch removed it.
+	Sides []envelopes.Balance `json:"sides"`
+
+	// Merged is the balance that was written to the index: the base, plus each branch's change to it.
+	Merged envelopes.Balance `json:"merged"`
+}
+
+// entry is the balance of an account or budget, distinguishing one that doesn't exist from one with no funds.
+type entry struct {
+	present bool
+	balance envelopes.Balance
+}
+
+func (e entry) equal(other entry) bool {
+	return e.present == other.present && e.balance.Equal(other.balance)
+}
+
+func (e entry) orNil() envelopes.Balance {
+	if !e.present {
+		return nil
+	}
+	return e.balance
+}
+
+// ThreeWay merges any number of States that descend from base. Each account, and each budget's own balance, is
+// considered separately: if only one side changed it, that change is kept. If several sides changed it differently, a
+// Conflict is reported, and the merged State holds the base plus the change from every side so that no spending is
+// lost. Conflicts are sorted by Path.
+func ThreeWay(base envelopes.State, sides ...envelopes.State) (envelopes.State, []Conflict) {
+	flatBase := flatten(base)
+	flatSides := make([]map[string]entry, len(sides))
+	paths := make(map[string]struct{}, len(flatBase))
+	for name := range flatBase {
+		paths[name] = struct{}{}
+	}
+	for i := range sides {
+		flatSides[i] = flatten(sides[i])
+		for name := range flatSides[i] {
+			paths[name] = struct{}{}
+		}
+	}
+
+	merged := make(map[string]entry, len(paths))
+	var conflicts []Conflict
+
+	for name := range paths {
+		original := flatBase[name]
+
+		var changes []entry
+		for i := range flatSides {
+			current := flatSides[i][name]
+			if current.equal(original) {
+				continue
+			}
+
+			duplicate := false
+			for _, change := range changes {
+				if change.equal(current) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				changes = append(changes, current)
+			}
+		}
+
+		switch len(changes) {
+		case 0:
+			merged[name] = original
+		case 1:
+			merged[name] = changes[0]
+		default:
+			combined := entry{balance: original.balance}
+			conflict := Conflict{
+				Path:  name,
+				Base:  original.orNil(),
+				Sides: make([]envelopes.Balance, len(flatSides)),
+			}
+			for i := range flatSides {
+				current := flatSides[i][name]
+				combined.present = combined.present || current.present
+				combined.balance = combined.balance.Add(current.balance.Sub(original.balance))
+				conflict.Sides[i] = current.orNil()
+			}
+			conflict.Merged = combined.balance
+			merged[name] = combined
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Path < conflicts[j].Path
+	})
+
+	return unflatten(merged), conflicts
+}
+
+// flatten lists every account and budget in a State, keyed by its path relative to the root of the index.
+func flatten(subject envelopes.State) map[string]entry {
+	retval := make(map[string]entry, len(subject.Accounts))
+
+	for name, balance := range subject.Accounts {
+		retval[path.Join(index.AccountsDir, name)] = entry{present: true, balance: balance}
+	}
+
+	var helper func(*envelopes.Budget, string)
+	helper = func(current *envelopes.Budget, name string) {
+		if current == nil {
+			return
+		}
+		retval[name] = entry{present: true, balance: current.Balance}
+		for childName, child := range current.Children {
+			helper(child, path.Join(name, childName))
+		}
+	}
+	helper(subject.Budget, index.BudgetDir)
+
+	return retval
+}
+
+// unflatten reverses flatten, creating any budgets needed to hold the children that are present.
+func unflatten(subject map[string]entry) envelopes.State {
+	retval := envelopes.State{
+		Accounts: envelopes.Accounts{},
+		Budget:   &envelopes.Budget{},
+	}
+
+	for name, current := range subject {
+		if !current.present {
+			continue
+		}
+
+		// Every name was produced by flatten, so it always refers to an account or budget.
+		_ = index.AdjustState(&retval, name, current.balance)
+	}
+
+	return retval
+}
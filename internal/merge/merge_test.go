@@ -0,0 +1,78 @@
+package merge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/marstr/envelopes"
+)
+
+func usd(dollars int64) envelopes.Balance {
+	return envelopes.Balance{"USD": big.NewRat(dollars, 1)}
+}
+
+func state(checking, groceries, rent int64) envelopes.State {
+	return envelopes.State{
+		Accounts: envelopes.Accounts{"checking": usd(checking)},
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"groceries": {Balance: usd(groceries)},
+				"rent":      {Balance: usd(rent)},
+			},
+		},
+	}
+}
+
+func TestThreeWay_clean(t *testing.T) {
+	base := state(1000, 300, 700)
+	ours := state(1000, 200, 800)
+	theirs := state(1000, 300, 700)
+	theirs.Budget.Children["savings"] = &envelopes.Budget{Balance: usd(0)}
+
+	got, conflicts := ThreeWay(base, ours, theirs)
+
+	if len(conflicts) != 0 {
+		t.Logf("unexpected conflicts: %+v", conflicts)
+		t.Fail()
+	}
+
+	expected := state(1000, 200, 800)
+	expected.Budget.Children["savings"] = &envelopes.Budget{Balance: usd(0)}
+	if !got.Equal(expected) {
+		t.Logf("\n\tgot:  %s\n\twant: %s", got, expected)
+		t.Fail()
+	}
+}
+
+func TestThreeWay_conflict(t *testing.T) {
+	base := state(1000, 300, 700)
+	ours := state(950, 250, 700)
+	theirs := state(980, 280, 700)
+	same := state(1000, 300, 700)
+	delete(same.Budget.Children, "rent")
+
+	got, conflicts := ThreeWay(base, ours, theirs, same)
+
+	expected := state(930, 230, 700)
+	delete(expected.Budget.Children, "rent")
+	if !got.Equal(expected) {
+		t.Logf("\n\tgot:  %s\n\twant: %s", got, expected)
+		t.Fail()
+	}
+
+	if len(conflicts) != 2 {
+		t.Logf("got %d conflicts, want 2: %+v", len(conflicts), conflicts)
+		t.FailNow()
+	}
+
+	if conflicts[0].Path != "accounts/checking" || conflicts[1].Path != "budget/groceries" {
+		t.Logf("unexpected conflicting paths: %q, %q", conflicts[0].Path, conflicts[1].Path)
+		t.Fail()
+	}
+
+	groceries := conflicts[1]
+	if !groceries.Base.Equal(usd(300)) || !groceries.Sides[0].Equal(usd(250)) || !groceries.Sides[1].Equal(usd(280)) || !groceries.Merged.Equal(usd(230)) {
+		t.Logf("unexpected conflict: %+v", groceries)
+		t.Fail()
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+// Summary describes what Collect did, or would have done.
+type Summary struct {
+	// Packed is the number of objects in the new pack file.
+	Packed int
+
+	// Pruned lists the unreachable objects that were deleted, because they had been unreachable for long enough.
+	Pruned []envelopes.ID
+
+	// Kept lists the unreachable objects that were left alone, because they haven't been unreachable for long enough.
+	Kept []envelopes.ID
+}
+
+// Collect rewrites the pack file of a repository to hold every reachable object, then removes the loose copies of
+// those objects. Unreachable loose objects that haven't been modified since cutoff are deleted. Unreachable objects
+// that had been packed are written back out as loose objects, so that they get a grace period of their own before
+// being deleted by a later Collect. If dryRun is true, nothing is changed.
+func Collect(ctx context.Context, repo *filesystem.Repository, reachable map[envelopes.ID]struct{}, cutoff time.Time, dryRun bool) (Summary, error) {
+	var summary Summary
+	repoLoc := repo.Root
+
+	old, err := Read(repoLoc)
+	if err != nil && !os.IsNotExist(err) {
+		return Summary{}, err
+	}
+
+	loose, err := ListLoose(repoLoc)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	looseByID := make(map[envelopes.ID]Loose, len(loose))
+	for _, entry := range loose {
+		looseByID[entry.ID] = entry
+	}
+
+	fetcher := Fetcher{Pack: old, Loose: repo.FileSystem}
+	packed := make(map[envelopes.ID][]byte, len(reachable))
+	for id := range reachable {
+		if err = ctx.Err(); err != nil {
+			return Summary{}, err
+		}
+
+		var contents []byte
+		contents, err = fetcher.Fetch(ctx, id)
+		if os.IsNotExist(err) {
+			// Not everything that has an ID is stored as an object of its own.
+			continue
+		} else if err != nil {
+			return Summary{}, err
+		}
+		packed[id] = contents
+	}
+	summary.Packed = len(packed)
+
+	var prune []Loose
+	for _, entry := range loose {
+		if _, ok := reachable[entry.ID]; ok {
+			continue
+		}
+
+		if entry.ModTime.Before(cutoff) {
+			prune = append(prune, entry)
+			summary.Pruned = append(summary.Pruned, entry.ID)
+		} else {
+			summary.Kept = append(summary.Kept, entry.ID)
+		}
+	}
+
+	var explode []envelopes.ID
+	for _, id := range old.IDs() {
+		_, isReachable := reachable[id]
+		_, isLoose := looseByID[id]
+		if !isReachable && !isLoose {
+			explode = append(explode, id)
+			summary.Kept = append(summary.Kept, id)
+		}
+	}
+
+	sortIDs(summary.Pruned)
+	sortIDs(summary.Kept)
+
+	if dryRun {
+		return summary, nil
+	}
+
+	// Everything that the old pack holds has to be somewhere else before the old pack is replaced.
+	for _, id := range explode {
+		contents, _ := old.Lookup(id)
+		err = repo.FileSystem.Stash(ctx, id, contents)
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+
+	err = Write(repoLoc, packed)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	// Only once the new pack is in place is it safe to remove the loose copies of what's in it.
+	for _, entry := range loose {
+		if _, ok := packed[entry.ID]; ok {
+			err = os.Remove(entry.Path)
+			if err != nil && !os.IsNotExist(err) {
+				return Summary{}, err
+			}
+		}
+	}
+
+	for _, entry := range prune {
+		err = os.Remove(entry.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return Summary{}, err
+		}
+	}
+
+	return summary, removeEmptyDirs(filepath.Join(repoLoc, filesystem.ObjectsDir))
+}
+
+// removeEmptyDirs deletes the directories inside of objectsDir that no longer hold any objects.
+func removeEmptyDirs(objectsDir string) error {
+	entries, err := os.ReadDir(objectsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		location := filepath.Join(objectsDir, entry.Name())
+		children, err := os.ReadDir(location)
+		if err != nil {
+			return err
+		}
+
+		if len(children) == 0 {
+			err = os.Remove(location)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package pack gathers the objects of a repository into a single file, so that reading history doesn't take a trip to
+// the disk for every transaction, state, budget and set of accounts.
+//
+// A pack file starts with a header and an index, sorted by ID, of where each object can be found in the rest of the
+// file. It ends with a SHA-1 checksum of everything that comes before it, so that damage can be detected.
+package pack
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+// Filename is the name of the pack file, which is kept alongside the loose objects of a repository.
+const Filename = "objects.pack"
+
+var magic = []byte("BRNLPAK1")
+
+const (
+	countSize = 4
+	entrySize = len(envelopes.ID{}) + 8 + 4
+)
+
+// ErrCorrupt is returned when a pack file can't be read because it has been damaged.
+type ErrCorrupt string
+
+func (e ErrCorrupt) Error() string {
+	return fmt.Sprintf("the pack file %q is corrupt", string(e))
+}
+
+type span struct {
+	offset uint64
+	length uint32
+}
+
+// Pack holds the contents of a pack file in memory.
+type Pack struct {
+	payloads []byte
+	index    map[envelopes.ID]span
+}
+
+// Location finds where the pack file of a repository is kept.
+func Location(repoLoc string) string {
+	return filepath.Join(repoLoc, filesystem.ObjectsDir, Filename)
+}
+
+// Read loads the pack file of a repository. If the repository doesn't have one, the error satisfies os.IsNotExist.
+func Read(repoLoc string) (*Pack, error) {
+	location := Location(repoLoc)
+	contents, err := os.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := len(magic) + countSize
+	if len(contents) < headerSize+sha1.Size || !bytes.Equal(contents[:len(magic)], magic) {
+		return nil, ErrCorrupt(location)
+	}
+
+	body, checksum := contents[:len(contents)-sha1.Size], contents[len(contents)-sha1.Size:]
+	if expected := sha1.Sum(body); !bytes.Equal(expected[:], checksum) {
+		return nil, ErrCorrupt(location)
+	}
+
+	count := int(binary.BigEndian.Uint32(body[len(magic):]))
+	payloadStart := headerSize + count*entrySize
+	if payloadStart > len(body) {
+		return nil, ErrCorrupt(location)
+	}
+
+	retval := &Pack{
+		payloads: body[payloadStart:],
+		index:    make(map[envelopes.ID]span, count),
+	}
+
+	for i := 0; i < count; i++ {
+		entry := body[headerSize+i*entrySize:]
+
+		var id envelopes.ID
+		copy(id[:], entry)
+		current := span{
+			offset: binary.BigEndian.Uint64(entry[len(id):]),
+			length: binary.BigEndian.Uint32(entry[len(id)+8:]),
+		}
+
+		if current.offset+uint64(current.length) > uint64(len(retval.payloads)) {
+			return nil, ErrCorrupt(location)
+		}
+		retval.index[id] = current
+	}
+
+	return retval, nil
+}
+
+// Lookup finds the contents of an object in the pack.
+func (p *Pack) Lookup(id envelopes.ID) ([]byte, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	found, ok := p.index[id]
+	if !ok {
+		return nil, false
+	}
+	return p.payloads[found.offset : found.offset+uint64(found.length)], true
+}
+
+// IDs lists every object in the pack, in order.
+func (p *Pack) IDs() []envelopes.ID {
+	if p == nil {
+		return nil
+	}
+
+	retval := make([]envelopes.ID, 0, len(p.index))
+	for id := range p.index {
+		retval = append(retval, id)
+	}
+	sortIDs(retval)
+	return retval
+}
+
+// Write replaces the pack file of a repository with one holding exactly the objects provided. The new pack is written
+// to a temporary file first, so that an interrupted Write leaves the old pack in place.
+func Write(repoLoc string, objects map[envelopes.ID][]byte) error {
+	ids := make([]envelopes.ID, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	var buf bytes.Buffer
+	buf.Write(magic)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ids)))
+
+	var offset uint64
+	for _, id := range ids {
+		buf.Write(id[:])
+		_ = binary.Write(&buf, binary.BigEndian, offset)
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(objects[id])))
+		offset += uint64(len(objects[id]))
+	}
+
+	for _, id := range ids {
+		buf.Write(objects[id])
+	}
+
+	checksum := sha1.Sum(buf.Bytes())
+	buf.Write(checksum[:])
+
+	location := Location(repoLoc)
+	err := os.MkdirAll(filepath.Dir(location), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	handle, err := os.CreateTemp(filepath.Dir(location), "."+Filename+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempName := handle.Name()
+
+	_, err = handle.Write(buf.Bytes())
+	if err == nil {
+		err = handle.Sync()
+	}
+	if closeErr := handle.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempName, location)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	return nil
+}
+
+// Loose describes an object that is stored in a file of its own, rather than in the pack.
+type Loose struct {
+	ID      envelopes.ID
+	Path    string
+	ModTime time.Time
+}
+
+// ListLoose finds every object in a repository that hasn't been packed, whichever way its objects are laid out.
+func ListLoose(repoLoc string) ([]Loose, error) {
+	objectsDir := filepath.Join(repoLoc, filesystem.ObjectsDir)
+
+	var retval []Loose
+	err := filepath.Walk(objectsDir, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(current) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(objectsDir, current)
+		if err != nil {
+			return err
+		}
+
+		raw := strings.TrimSuffix(strings.ReplaceAll(filepath.ToSlash(rel), "/", ""), ".json")
+		var id envelopes.ID
+		decoded, err := hex.DecodeString(raw)
+		if err != nil || len(decoded) != len(id) {
+			// Not every file in the objects directory has to be an object.
+			return nil
+		}
+
+		copy(id[:], decoded)
+		retval = append(retval, Loose{ID: id, Path: current, ModTime: info.ModTime()})
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return retval, err
+}
+
+// ListObjects finds every object in a repository, whether or not it has been packed.
+func ListObjects(repoLoc string) ([]envelopes.ID, error) {
+	loose, err := ListLoose(repoLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := Read(repoLoc)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	retval := packed.IDs()
+	for _, entry := range loose {
+		if _, ok := packed.Lookup(entry.ID); !ok {
+			retval = append(retval, entry.ID)
+		}
+	}
+	sortIDs(retval)
+	return retval, nil
+}
+
+func sortIDs(ids []envelopes.ID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+}
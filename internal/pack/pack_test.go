@@ -0,0 +1,180 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+)
+
+func commit(t *testing.T, repo *filesystem.Repository, dollars int64, parents ...envelopes.ID) envelopes.ID {
+	transaction := envelopes.Transaction{
+		State: &envelopes.State{
+			Accounts: envelopes.Accounts{"checking": envelopes.Balance{"USD": big.NewRat(dollars, 1)}},
+			Budget:   &envelopes.Budget{Balance: envelopes.Balance{"USD": big.NewRat(dollars, 1)}},
+		},
+		Parents:     parents,
+		EnteredTime: time.Date(2026, time.October, 17, 0, 0, int(dollars), 0, time.UTC),
+	}
+
+	err := repo.WriteTransaction(context.Background(), transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction.ID()
+}
+
+func TestWriteRead(t *testing.T) {
+	repoLoc := t.TempDir()
+
+	objects := map[envelopes.ID][]byte{
+		{1}: []byte(`{"first":true}`),
+		{2}: []byte(`{"second":true}`),
+		{3}: {},
+	}
+
+	err := Write(repoLoc, objects)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	packed, err := Read(repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got := packed.IDs(); len(got) != len(objects) {
+		t.Logf("got %d objects, want %d", len(got), len(objects))
+		t.Fail()
+	}
+
+	for id, want := range objects {
+		got, ok := packed.Lookup(id)
+		if !ok || string(got) != string(want) {
+			t.Logf("object %s\n\tgot:  %q (found: %v)\n\twant: %q", id, got, ok, want)
+			t.Fail()
+		}
+	}
+
+	if _, ok := packed.Lookup(envelopes.ID{4}); ok {
+		t.Log("found an object that was never packed")
+		t.Fail()
+	}
+
+	contents, err := os.ReadFile(Location(repoLoc))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	contents[len(contents)/2] ^= 0xff
+	err = os.WriteFile(Location(repoLoc), contents, 0660)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = Read(repoLoc); !errors.As(err, new(ErrCorrupt)) {
+		t.Logf("expected a damaged pack to be rejected, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestCollect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLoc := t.TempDir()
+	repo, err := OpenRepositoryWithCache(ctx, repoLoc, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	first := commit(t, repo, 100)
+	second := commit(t, repo, 90, first)
+	orphan := commit(t, repo, 80, first)
+
+	var transaction envelopes.Transaction
+	reachable := make(map[envelopes.ID]struct{})
+	for _, id := range []envelopes.ID{first, second} {
+		if err = repo.LoadTransaction(ctx, id, &transaction); err != nil {
+			t.Error(err)
+			return
+		}
+		reachable[id] = struct{}{}
+		reachable[transaction.State.ID()] = struct{}{}
+		reachable[transaction.State.Accounts.ID()] = struct{}{}
+		reachable[transaction.State.Budget.ID()] = struct{}{}
+	}
+
+	// The orphan was only just written, so it should survive being collected.
+	summary, err := Collect(ctx, repo, reachable, time.Now().Add(-time.Hour), false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if summary.Packed != len(reachable) || len(summary.Pruned) != 0 || len(summary.Kept) == 0 {
+		t.Logf("unexpected summary: %+v", summary)
+		t.Fail()
+	}
+
+	loose, err := ListLoose(repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, entry := range loose {
+		if _, ok := reachable[entry.ID]; ok {
+			t.Logf("%s was packed, but its loose copy was left behind", entry.ID)
+			t.Fail()
+		}
+	}
+
+	reopened, err := OpenRepositoryWithCache(ctx, repoLoc, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, id := range []envelopes.ID{second, orphan} {
+		if err = reopened.LoadTransaction(ctx, id, &transaction); err != nil {
+			t.Logf("couldn't load %s after collecting: %v", id, err)
+			t.Fail()
+		}
+	}
+
+	summary, err = Collect(ctx, reopened, reachable, time.Now().Add(time.Hour), false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(summary.Pruned) == 0 || len(summary.Kept) != 0 {
+		t.Logf("expected the orphan to be pruned, got: %+v", summary)
+		t.Fail()
+	}
+
+	reopened, err = OpenRepositoryWithCache(ctx, repoLoc, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = reopened.LoadTransaction(ctx, orphan, &transaction); err == nil {
+		t.Log("expected the orphan to have been deleted")
+		t.Fail()
+	}
+
+	if err = reopened.LoadTransaction(ctx, second, &transaction); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pack
+
+import (
+	"context"
+	"os"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+	"github.com/marstr/envelopes/persist/filesystem"
+	persistJson "github.com/marstr/envelopes/persist/json"
+)
+
+// Fetcher reads objects out of a Pack, falling back to the loose objects of a repository for anything that hasn't been
+// packed yet.
+type Fetcher struct {
+	Pack  *Pack
+	Loose persist.Fetcher
+}
+
+// Fetch finds the contents of an object.
+func (f Fetcher) Fetch(ctx context.Context, id envelopes.ID) ([]byte, error) {
+	if found, ok := f.Pack.Lookup(id); ok {
+		return found, nil
+	}
+	return f.Loose.Fetch(ctx, id)
+}
+
+// OpenRepositoryWithCache opens a repository the same way as filesystem.OpenRepositoryWithCache, except that objects
+// are read from the repository's pack file when they can be. New objects are still written as loose files.
+func OpenRepositoryWithCache(ctx context.Context, loc string, cacheSize uint) (*filesystem.Repository, error) {
+	repo, err := filesystem.OpenRepositoryWithCache(ctx, loc, cacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := Read(loc)
+	if os.IsNotExist(err) {
+		return repo, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	config, err := filesystem.LoadConfig(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	loose := repo.FileSystem
+	fetcher := Fetcher{Pack: packed, Loose: loose}
+	cache := persist.NewCache(cacheSize)
+
+	switch config.Objects.Version {
+	case 1:
+		cache.Loader, err = persistJson.NewLoaderV1WithLoopback(fetcher, cache)
+		if err == nil {
+			cache.Writer, err = persistJson.NewWriterV1WithLoopback(loose, cache)
+		}
+	case 2:
+		cache.Loader, err = persistJson.NewLoaderV2WithLoopback(fetcher, cache)
+		if err == nil {
+			cache.Writer, err = persistJson.NewWriterV2WithLoopback(loose, cache)
+		}
+	case 3:
+		cache.Loader, err = persistJson.NewLoaderV3WithLoopback(fetcher, cache)
+		if err == nil {
+			cache.Writer, err = persistJson.NewWriterV3WithLoopback(loose, cache)
+		}
+	default:
+		return nil, filesystem.ErrUnsupportedConfiguration(*config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repo.Loader = cache
+	repo.Writer = cache
+	return repo, nil
+}
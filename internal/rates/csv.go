@@ -0,0 +1,105 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rates
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/marstr/envelopes"
+	"github.com/spf13/cast"
+)
+
+// ErrBadRow is returned when a line of a rates CSV file can't be understood.
+type ErrBadRow struct {
+	Line   int
+	Reason string
+}
+
+func (e ErrBadRow) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// ReadCSV parses exchange rates from comma separated values. Each row holds a date, an asset, and how many units of
+// another asset one unit of it was worth on that date. An optional fourth column names the other asset; when it is
+// absent, defaultIn is used. A header row is skipped if present.
+//
+//	date,asset,rate,in
+//	2026-10-01,EUR,1.08,USD
+//	2026-10-01,VTSAX,131.42,USD
+func ReadCSV(ctx context.Context, input io.Reader, defaultIn envelopes.AssetType) ([]Rate, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var retval []Rate
+	for line := 1; ; line++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 3 || len(record) > 4 {
+			return nil, ErrBadRow{Line: line, Reason: fmt.Sprintf("expected 3 or 4 columns, found %d", len(record))}
+		}
+
+		date, err := cast.ToTimeE(strings.TrimSpace(record[0]))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, ErrBadRow{Line: line, Reason: fmt.Sprintf("%q is not a date", record[0])}
+		}
+
+		value, ok := new(big.Rat).SetString(strings.TrimSpace(record[2]))
+		if !ok || value.Sign() <= 0 {
+			return nil, ErrBadRow{Line: line, Reason: fmt.Sprintf("%q is not a positive rate", record[2])}
+		}
+
+		current := Rate{
+			Date:  date,
+			Asset: envelopes.AssetType(strings.TrimSpace(record[1])),
+			In:    defaultIn,
+			Value: value,
+		}
+		if len(record) == 4 && strings.TrimSpace(record[3]) != "" {
+			current.In = envelopes.AssetType(strings.TrimSpace(record[3]))
+		}
+
+		if current.Asset == "" || current.Asset == current.In {
+			return nil, ErrBadRow{Line: line, Reason: "a rate must convert between two different assets"}
+		}
+
+		retval = append(retval, current)
+	}
+
+	return retval, nil
+}
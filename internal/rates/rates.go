@@ -0,0 +1,288 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package rates keeps track of what assets were worth relative to one another over time, so that balances holding a
+// mix of currencies, shares, or other assets can be valued as a single amount.
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// Filename is the name of the file, in a repository's metadata directory, that holds its exchange rates.
+const Filename = "rates.json"
+
+// Rate records that, starting on Date, one unit of Asset was worth Value units of In.
+type Rate struct {
+	Date  time.Time           `json:"date"`
+	Asset envelopes.AssetType `json:"asset"`
+	In    envelopes.AssetType `json:"in"`
+	Value *big.Rat            `json:"value"`
+}
+
+// ErrNoRate is returned when there isn't enough information to convert between two assets as of a point in time.
+type ErrNoRate struct {
+	From envelopes.AssetType
+	To   envelopes.AssetType
+	When time.Time
+}
+
+func (e ErrNoRate) Error() string {
+	return fmt.Sprintf("no rate is known for converting %s to %s on %s", e.From, e.To, e.When.Format("2006-01-02"))
+}
+
+// Store is a collection of dated exchange rates.
+type Store struct {
+	// rates is keyed by the pair of assets that they convert between, and sorted by date.
+	rates map[pair][]Rate
+}
+
+type pair struct {
+	Asset envelopes.AssetType
+	In    envelopes.AssetType
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{rates: make(map[pair][]Rate)}
+}
+
+// Add records a Rate, replacing any other Rate between the same two assets on the same day. Rates take effect at the
+// beginning of the day they are dated.
+func (s *Store) Add(rate Rate) {
+	year, month, day := rate.Date.Date()
+	rate.Date = time.Date(year, month, day, 0, 0, 0, 0, rate.Date.Location())
+
+	key := pair{Asset: rate.Asset, In: rate.In}
+	existing := s.rates[key]
+
+	i := sort.Search(len(existing), func(i int) bool {
+		return !existing[i].Date.Before(rate.Date)
+	})
+
+	if i < len(existing) && existing[i].Date.Equal(rate.Date) {
+		existing[i] = rate
+		return
+	}
+
+	existing = append(existing, Rate{})
+	copy(existing[i+1:], existing[i:])
+	existing[i] = rate
+	s.rates[key] = existing
+}
+
+// Rates lists every Rate in the Store, ordered by the assets they convert between, then by date.
+func (s *Store) Rates() []Rate {
+	keys := make([]pair, 0, len(s.rates))
+	for key := range s.rates {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Asset != keys[j].Asset {
+			return keys[i].Asset < keys[j].Asset
+		}
+		return keys[i].In < keys[j].In
+	})
+
+	var retval []Rate
+	for _, key := range keys {
+		retval = append(retval, s.rates[key]...)
+	}
+	return retval
+}
+
+// Lookup finds how many units of to a single unit of from was worth at a point in time, using the most recent Rate
+// recorded on or before then. Rates may be used in either direction, and may be chained through one other asset.
+func (s *Store) Lookup(from, to envelopes.AssetType, when time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	if found, ok := s.lookupDirect(from, to, when); ok {
+		return found, nil
+	}
+
+	intermediates := make(map[envelopes.AssetType]struct{})
+	for key := range s.rates {
+		if key.Asset == from {
+			intermediates[key.In] = struct{}{}
+		} else if key.In == from {
+			intermediates[key.Asset] = struct{}{}
+		}
+	}
+
+	candidates := make([]envelopes.AssetType, 0, len(intermediates))
+	for intermediate := range intermediates {
+		candidates = append(candidates, intermediate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	for _, intermediate := range candidates {
+		first, ok := s.lookupDirect(from, intermediate, when)
+		if !ok {
+			continue
+		}
+		second, ok := s.lookupDirect(intermediate, to, when)
+		if !ok {
+			continue
+		}
+		return new(big.Rat).Mul(first, second), nil
+	}
+
+	return nil, ErrNoRate{From: from, To: to, When: when}
+}
+
+func (s *Store) lookupDirect(from, to envelopes.AssetType, when time.Time) (*big.Rat, bool) {
+	if found, ok := mostRecent(s.rates[pair{Asset: from, In: to}], when); ok {
+		return new(big.Rat).Set(found.Value), true
+	}
+
+	if found, ok := mostRecent(s.rates[pair{Asset: to, In: from}], when); ok && found.Value.Sign() != 0 {
+		return new(big.Rat).Inv(found.Value), true
+	}
+
+	return nil, false
+}
+
+func mostRecent(candidates []Rate, when time.Time) (Rate, bool) {
+	i := sort.Search(len(candidates), func(i int) bool {
+		return candidates[i].Date.After(when)
+	})
+	if i == 0 {
+		return Rate{}, false
+	}
+	return candidates[i-1], true
+}
+
+// Convert values every asset in a Balance in terms of a single asset, using the rates in effect at a point in time.
+func (s *Store) Convert(subject envelopes.Balance, to envelopes.AssetType, when time.Time) (envelopes.Balance, error) {
+	total := new(big.Rat)
+	for asset, magnitude := range subject {
+		if magnitude.Sign() == 0 {
+			continue
+		}
+
+		rate, err := s.Lookup(asset, to, when)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, new(big.Rat).Mul(magnitude, rate))
+	}
+	return envelopes.Balance{to: total}, nil
+}
+
+// ConvertState values every account and budget in a State in terms of a single asset, using the rates in effect at a
+// point in time.
+func (s *Store) ConvertState(subject envelopes.State, to envelopes.AssetType, when time.Time) (envelopes.State, error) {
+	var retval envelopes.State
+	var err error
+
+	if subject.Accounts != nil {
+		retval.Accounts = make(envelopes.Accounts, len(subject.Accounts))
+		for name, bal := range subject.Accounts {
+			retval.Accounts[name], err = s.Convert(bal, to, when)
+			if err != nil {
+				return envelopes.State{}, err
+			}
+		}
+	}
+
+	if subject.Budget != nil {
+		retval.Budget, err = s.ConvertBudget(*subject.Budget, to, when)
+		if err != nil {
+			return envelopes.State{}, err
+		}
+	}
+
+	return retval, nil
+}
+
+// ConvertBudget values a Budget, and each of its children, in terms of a single asset using the rates in effect at a
+// point in time.
+func (s *Store) ConvertBudget(subject envelopes.Budget, to envelopes.AssetType, when time.Time) (*envelopes.Budget, error) {
+	var err error
+	retval := &envelopes.Budget{}
+
+	if len(subject.Balance) > 0 {
+		retval.Balance, err = s.Convert(subject.Balance, to, when)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if subject.Children != nil {
+		retval.Children = make(map[string]*envelopes.Budget, len(subject.Children))
+		for name, child := range subject.Children {
+			retval.Children[name], err = s.ConvertBudget(*child, to, when)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return retval, nil
+}
+
+// Load reads the rates that have been recorded in a repository. If none have been recorded, an empty Store is
+// returned.
+func Load(_ context.Context, repoLoc string) (*Store, error) {
+	retval := NewStore()
+
+	contents, err := os.ReadFile(filepath.Join(repoLoc, Filename))
+	if os.IsNotExist(err) {
+		return retval, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []Rate
+	err = json.Unmarshal(contents, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", Filename, err)
+	}
+
+	for _, entry := range entries {
+		retval.Add(entry)
+	}
+	return retval, nil
+}
+
+// Write saves every Rate in a Store to a repository.
+func Write(_ context.Context, repoLoc string, store *Store) error {
+	const filePermissions = 0660
+
+	entries := store.Rates()
+	if entries == nil {
+		entries = []Rate{}
+	}
+
+	toWrite, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(repoLoc, Filename), toWrite, filePermissions)
+}
@@ -0,0 +1,156 @@
+package rates
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStore_Lookup(t *testing.T) {
+	store := NewStore()
+	store.Add(Rate{Date: date(2026, time.January, 1), Asset: "EUR", In: "USD", Value: big.NewRat(110, 100)})
+	store.Add(Rate{Date: date(2026, time.June, 1), Asset: "EUR", In: "USD", Value: big.NewRat(120, 100)})
+	store.Add(Rate{Date: date(2026, time.March, 1), Asset: "EUR", In: "USD", Value: big.NewRat(115, 100)})
+	store.Add(Rate{Date: date(2026, time.March, 1).Add(5 * time.Hour), Asset: "EUR", In: "USD", Value: big.NewRat(116, 100)})
+	store.Add(Rate{Date: date(2026, time.January, 1), Asset: "FUND", In: "USD", Value: big.NewRat(100, 1)})
+
+	testCases := []struct {
+		from     envelopes.AssetType
+		to       envelopes.AssetType
+		when     time.Time
+		expected *big.Rat
+	}{
+		{"USD", "USD", date(2020, time.January, 1), big.NewRat(1, 1)},
+		{"EUR", "USD", date(2026, time.February, 14), big.NewRat(110, 100)},
+		{"EUR", "USD", date(2026, time.March, 1), big.NewRat(116, 100)},
+		{"EUR", "USD", date(2026, time.December, 31), big.NewRat(120, 100)},
+		{"USD", "EUR", date(2026, time.July, 4), big.NewRat(100, 120)},
+		{"FUND", "EUR", date(2026, time.July, 4), big.NewRat(10000, 120)},
+	}
+
+	for _, tc := range testCases {
+		got, err := store.Lookup(tc.from, tc.to, tc.when)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got.Cmp(tc.expected) != 0 {
+			t.Logf("%s to %s on %s\n\tgot:  %s\n\twant: %s", tc.from, tc.to, tc.when.Format("2006-01-02"), got.RatString(), tc.expected.RatString())
+			t.Fail()
+		}
+	}
+
+	if _, err := store.Lookup("EUR", "USD", date(2025, time.December, 31)); err == nil {
+		t.Log("expected an error when no rate was in effect yet")
+		t.Fail()
+	}
+
+	if got := len(store.Rates()); got != 4 {
+		t.Logf("got %d rates, want 4", got)
+		t.Fail()
+	}
+}
+
+func TestStore_Convert(t *testing.T) {
+	store := NewStore()
+	store.Add(Rate{Date: date(2026, time.January, 1), Asset: "EUR", In: "USD", Value: big.NewRat(110, 100)})
+
+	subject := envelopes.Balance{"USD": big.NewRat(10, 1), "EUR": big.NewRat(100, 1), "GBP": big.NewRat(0, 1)}
+	got, err := store.Convert(subject, "USD", date(2026, time.October, 17))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if want := (envelopes.Balance{"USD": big.NewRat(120, 1)}); !got.Equal(want) {
+		t.Logf("\n\tgot:  %v\n\twant: %v", got, want)
+		t.Fail()
+	}
+}
+
+func TestLoadWrite_roundtrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLoc, err := os.MkdirTemp("", "baronial_rates_")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(repoLoc)
+
+	empty, err := Load(ctx, repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(empty.Rates()) != 0 {
+		t.Log("expected a repository without a rates file to have no rates")
+		t.Fail()
+	}
+
+	store := NewStore()
+	store.Add(Rate{Date: date(2026, time.January, 1), Asset: "EUR", In: "USD", Value: big.NewRat(110, 100)})
+	err = Write(ctx, repoLoc, store)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	reloaded, err := Load(ctx, repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := reloaded.Lookup("EUR", "USD", date(2026, time.January, 2))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Cmp(big.NewRat(110, 100)) != 0 {
+		t.Logf("got %s after reloading", got.RatString())
+		t.Fail()
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const raw = `date,asset,rate,in
+2026-10-01,EUR,1.08,USD
+2026-10-01, VTSAX, 131.42
+`
+
+	got, err := ReadCSV(ctx, strings.NewReader(raw), "USD")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(got) != 2 {
+		t.Logf("got %d rates, want 2", len(got))
+		t.FailNow()
+	}
+
+	if got[1].Asset != "VTSAX" || got[1].In != "USD" || got[1].Value.Cmp(big.NewRat(13142, 100)) != 0 {
+		t.Logf("unexpected rate: %+v", got[1])
+		t.Fail()
+	}
+
+	if _, err = ReadCSV(ctx, strings.NewReader("2026-10-01,EUR,none\n"), "USD"); err == nil {
+		t.Log("expected an error for a rate that isn't a number")
+		t.Fail()
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package remote keeps separate copies of a repository in sync, by copying the transactions that one is missing from
+// the other and moving branches forward to match.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marstr/envelopes/persist/filesystem"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+// Filename is the name of the file, in a repository's metadata directory, that lists its remotes.
+const Filename = "remotes.json"
+
+// DefaultName is the remote that is used when one isn't specified.
+const DefaultName = "origin"
+
+// Remote is another copy of a repository that transactions can be exchanged with.
+type Remote struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// ErrUnknownRemote is returned when a remote is requested by a name that hasn't been added.
+type ErrUnknownRemote string
+
+func (e ErrUnknownRemote) Error() string {
+	return fmt.Sprintf("no remote named %q has been added", string(e))
+}
+
+// ErrRemoteExists is returned when adding a remote with a name that is already in use.
+type ErrRemoteExists string
+
+func (e ErrRemoteExists) Error() string {
+	return fmt.Sprintf("a remote named %q already exists", string(e))
+}
+
+// ErrBadName is returned when a remote is given a name that couldn't be used to refer to its branches.
+type ErrBadName string
+
+func (e ErrBadName) Error() string {
+	return fmt.Sprintf("%q can't be used as the name of a remote", string(e))
+}
+
+// ErrUnsupportedTransport is returned when a remote's location can't be reached by any means Baronial knows about.
+type ErrUnsupportedTransport string
+
+func (e ErrUnsupportedTransport) Error() string {
+	return fmt.Sprintf("%q isn't supported as a remote location, only directories are", string(e))
+}
+
+// ErrNotRepository is returned when a remote's location doesn't hold a repository.
+type ErrNotRepository string
+
+func (e ErrNotRepository) Error() string {
+	return fmt.Sprintf("%q doesn't contain a Baronial repository", string(e))
+}
+
+// Load reads the list of remotes that have been added to a repository, sorted by name.
+func Load(_ context.Context, repoLoc string) ([]Remote, error) {
+	contents, err := os.ReadFile(filepath.Join(repoLoc, Filename))
+	if os.IsNotExist(err) {
+		return []Remote{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var retval []Remote
+	err = json.Unmarshal(contents, &retval)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", Filename, err)
+	}
+
+	sort.Slice(retval, func(i, j int) bool {
+		return retval[i].Name < retval[j].Name
+	})
+	return retval, nil
+}
+
+// Write replaces the list of remotes that have been added to a repository.
+func Write(_ context.Context, repoLoc string, remotes []Remote) error {
+	const filePermissions = 0660
+
+	if remotes == nil {
+		remotes = []Remote{}
+	}
+
+	toWrite, err := json.MarshalIndent(remotes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(repoLoc, Filename), toWrite, filePermissions)
+}
+
+// Find looks up a remote by name.
+func Find(remotes []Remote, name string) (Remote, error) {
+	for _, entry := range remotes {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return Remote{}, ErrUnknownRemote(name)
+}
+
+// Add records a new remote in a repository.
+func Add(ctx context.Context, repoLoc string, name, location string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return ErrBadName(name)
+	}
+
+	remotes, err := Load(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	if _, err = Find(remotes, name); err == nil {
+		return ErrRemoteExists(name)
+	}
+
+	return Write(ctx, repoLoc, append(remotes, Remote{Name: name, Location: location}))
+}
+
+// Remove forgets about a remote, along with what was known about its branches.
+func Remove(ctx context.Context, repoLoc string, name string) error {
+	remotes, err := Load(ctx, repoLoc)
+	if err != nil {
+		return err
+	}
+
+	pared := remotes[:0]
+	for _, entry := range remotes {
+		if entry.Name != name {
+			pared = append(pared, entry)
+		}
+	}
+
+	if len(pared) == len(remotes) {
+		return ErrUnknownRemote(name)
+	}
+
+	err = Write(ctx, repoLoc, pared)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(trackingDir(repoLoc, name))
+}
+
+// Open finds the repository that a Remote is referring to. Locations may either be the root of an index, or a
+// repository's metadata directory.
+func Open(ctx context.Context, location string) (*filesystem.Repository, error) {
+	repoLoc, err := metadataDir(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return pack.OpenRepositoryWithCache(ctx, repoLoc, 10000)
+}
+
+// metadataDir finds the directory that holds the objects and refs of the repository a Remote is referring to.
+func metadataDir(location string) (string, error) {
+	if scheme, _, found := strings.Cut(location, "://"); found {
+		if scheme != "file" {
+			return "", ErrUnsupportedTransport(location)
+		}
+		location = strings.TrimPrefix(location, "file://")
+	}
+
+	repoLoc := location
+	if info, err := os.Stat(filepath.Join(location, index.RepoName)); err == nil && info.IsDir() {
+		repoLoc = filepath.Join(location, index.RepoName)
+	}
+
+	// Opening a directory without a repository in it would quietly create one, so make sure there are refs first.
+	if info, err := os.Stat(filepath.Join(repoLoc, "refs")); err != nil || !info.IsDir() {
+		return "", ErrNotRepository(location)
+	}
+
+	return repoLoc, nil
+}
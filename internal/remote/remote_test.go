@@ -0,0 +1,268 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist/filesystem"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/lock"
+)
+
+func newRepository(t *testing.T) (string, *filesystem.Repository) {
+	loc, err := os.MkdirTemp("", "baronial_remote_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(loc) })
+
+	repo, err := filesystem.OpenRepository(context.Background(), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.WriteBranch(context.Background(), "master", envelopes.ID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc, repo
+}
+
+func commit(t *testing.T, repo *filesystem.Repository, dollars int64, parents ...envelopes.ID) envelopes.ID {
+	transaction := envelopes.Transaction{
+		State: &envelopes.State{
+			Accounts: envelopes.Accounts{"checking": envelopes.Balance{"USD": big.NewRat(dollars, 1)}},
+			Budget:   &envelopes.Budget{Balance: envelopes.Balance{"USD": big.NewRat(dollars, 1)}},
+		},
+		Parents:     parents,
+		EnteredTime: time.Date(2026, time.October, 17, 0, 0, int(dollars), 0, time.UTC),
+	}
+
+	err := repo.WriteTransaction(context.Background(), transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := transaction.ID()
+	err = repo.WriteBranch(context.Background(), "master", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestAddRemove(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repoLoc, _ := newRepository(t)
+
+	err := Add(ctx, repoLoc, "origin", "/elsewhere")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = Add(ctx, repoLoc, "origin", "/another"); !errors.As(err, new(ErrRemoteExists)) {
+		t.Logf("expected a duplicate remote to be rejected, got: %v", err)
+		t.Fail()
+	}
+
+	if err = Add(ctx, repoLoc, "a/b", "/another"); !errors.As(err, new(ErrBadName)) {
+		t.Logf("expected a name with a slash to be rejected, got: %v", err)
+		t.Fail()
+	}
+
+	remotes, err := Load(ctx, repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got, err := Find(remotes, "origin"); err != nil || got.Location != "/elsewhere" {
+		t.Logf("unexpected remote %+v (error: %v)", got, err)
+		t.Fail()
+	}
+
+	err = Remove(ctx, repoLoc, "origin")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = Remove(ctx, repoLoc, "origin"); !errors.As(err, new(ErrUnknownRemote)) {
+		t.Logf("expected removing a missing remote to fail, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestFetchPush(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	theirLoc, theirs := newRepository(t)
+	ourLoc, ours := newRepository(t)
+	origin := Remote{Name: "origin", Location: theirLoc}
+
+	first := commit(t, theirs, 100)
+	second := commit(t, theirs, 90, first)
+
+	updates, err := Fetch(ctx, ours, ourLoc, origin)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(updates) != 1 || updates[0].Branch != "origin/master" || !updates[0].New.Equal(second) {
+		t.Logf("unexpected updates: %v", updates)
+		t.Fail()
+	}
+
+	var copied envelopes.Transaction
+	if err = ours.LoadTransaction(ctx, first, &copied); err != nil {
+		t.Logf("expected the first transaction to have been copied: %v", err)
+		t.Fail()
+	}
+
+	tracked, err := ReadTracking(ctx, ourLoc, "origin", "master")
+	if err != nil || !tracked.Equal(second) {
+		t.Logf("origin/master was %s (error: %v), want %s", tracked, err, second)
+		t.Fail()
+	}
+
+	// Both sides move on from the second transaction, so pushing ours would discard theirs.
+	commit(t, theirs, 80, second)
+	ourThird := commit(t, ours, 70, second)
+
+	if _, err = Push(ctx, ours, ourLoc, origin, "master", false); !errors.As(err, new(ErrNonFastForward)) {
+		t.Logf("expected a diverged push to be rejected, got: %v", err)
+		t.Fail()
+	}
+
+	held, err := lock.TryAcquire(theirLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err = Push(ctx, ours, ourLoc, origin, "master", true); !errors.As(err, new(lock.ErrLocked)) {
+		t.Logf("expected pushing to a locked repository to be rejected, got: %v", err)
+		t.Fail()
+	}
+
+	err = held.Release()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	update, err := Push(ctx, ours, ourLoc, origin, "master", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if head, err := theirs.ReadBranch(ctx, "master"); err != nil || !head.Equal(ourThird) || !update.New.Equal(ourThird) {
+		t.Logf("their master is %s (error: %v), want %s", head, err, ourThird)
+		t.Fail()
+	}
+}
+
+func TestPush_checkedOut(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	theirLoc, err := os.MkdirTemp("", "baronial_remote_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(theirLoc) })
+
+	err = os.Mkdir(filepath.Join(theirLoc, index.RepoName), 0770)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	theirs, err := filesystem.OpenRepository(ctx, filepath.Join(theirLoc, index.RepoName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := commit(t, theirs, 100)
+	err = theirs.SetCurrent(ctx, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ourLoc, ours := newRepository(t)
+	origin := Remote{Name: "origin", Location: theirLoc}
+	_, err = Fetch(ctx, ours, ourLoc, origin)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	second := commit(t, ours, 90, first)
+
+	if _, err = Push(ctx, ours, ourLoc, origin, "master", false); !errors.As(err, new(ErrCheckedOut)) {
+		t.Logf("expected pushing to a checked out branch to be rejected, got: %v", err)
+		t.Fail()
+	}
+
+	if head, err := theirs.ReadBranch(ctx, "master"); err != nil || !head.Equal(first) {
+		t.Logf("their master moved to %s (error: %v), want %s", head, err, first)
+		t.Fail()
+	}
+
+	if _, err = Push(ctx, ours, ourLoc, origin, "master", true); err != nil {
+		t.Logf("expected a forced push to succeed, got: %v", err)
+		t.Fail()
+	}
+
+	if head, err := theirs.ReadBranch(ctx, "master"); err != nil || !head.Equal(second) {
+		t.Logf("their master is %s (error: %v), want %s", head, err, second)
+		t.Fail()
+	}
+}
+
+func TestIsAncestor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, repo := newRepository(t)
+	first := commit(t, repo, 100)
+	left := commit(t, repo, 90, first)
+	right := commit(t, repo, 80, first)
+	merged := commit(t, repo, 70, left, right)
+
+	testCases := []struct {
+		ancestor   envelopes.ID
+		descendant envelopes.ID
+		expected   bool
+	}{
+		{envelopes.ID{}, first, true},
+		{first, first, true},
+		{first, merged, true},
+		{right, merged, true},
+		{left, right, false},
+		{merged, first, false},
+	}
+
+	for _, tc := range testCases {
+		got, err := IsAncestor(ctx, repo, tc.ancestor, tc.descendant)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got != tc.expected {
+			t.Logf("IsAncestor(%s, %s)\n\tgot:  %v\n\twant: %v", tc.ancestor, tc.descendant, got, tc.expected)
+			t.Fail()
+		}
+	}
+}
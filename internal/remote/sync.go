@@ -0,0 +1,263 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+
+	"github.com/marstr/baronial/internal/index"
+	"github.com/marstr/baronial/internal/lock"
+	"github.com/marstr/baronial/internal/pack"
+)
+
+// Update describes a branch that was moved while synchronizing with a remote.
+type Update struct {
+	Branch string
+	Old    envelopes.ID
+	New    envelopes.ID
+}
+
+func (u Update) String() string {
+	if u.Old.Equal(envelopes.ID{}) {
+		return fmt.Sprintf("%s\t(new)..%s", u.Branch, u.New)
+	}
+	return fmt.Sprintf("%s\t%s..%s", u.Branch, u.Old, u.New)
+}
+
+// ErrNonFastForward is returned when pushing a branch would discard transactions that are only on the remote.
+type ErrNonFastForward string
+
+func (e ErrNonFastForward) Error() string {
+	return fmt.Sprintf("the remote's %q has transactions that aren't present locally, pull before pushing", string(e))
+}
+
+// ErrCheckedOut is returned when pushing would move a branch out from under the working copy that has it checked out.
+type ErrCheckedOut string
+
+func (e ErrCheckedOut) Error() string {
+	return fmt.Sprintf("the remote has %q checked out, and moving it would leave its index out of date", string(e))
+}
+
+// ErrNoBranch is returned when a branch is requested that doesn't exist.
+type ErrNoBranch string
+
+func (e ErrNoBranch) Error() string {
+	return fmt.Sprintf("no branch named %q was found", string(e))
+}
+
+type loaderWriter interface {
+	persist.Loader
+	persist.Writer
+}
+
+// Copy writes each Transaction in the history of heads that dest doesn't have yet. Transactions are written oldest
+// first, so that if copying is interrupted, dest never has a Transaction without its ancestors. It returns the number
+// of transactions that were copied.
+func Copy(ctx context.Context, src persist.Loader, dest loaderWriter, heads ...envelopes.ID) (int, error) {
+	var missing []envelopes.Transaction
+
+	nonEmpty := make([]envelopes.ID, 0, len(heads))
+	for _, head := range heads {
+		if !head.Equal(envelopes.ID{}) {
+			nonEmpty = append(nonEmpty, head)
+		}
+	}
+
+	walker := persist.Walker{Loader: src}
+	err := walker.Walk(ctx, func(ctx context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		var existing envelopes.Transaction
+		if dest.LoadTransaction(ctx, id, &existing) == nil {
+			return persist.ErrSkipAncestors{}
+		}
+
+		missing = append(missing, transaction)
+		return nil
+	}, nonEmpty...)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		err = dest.WriteTransaction(ctx, missing[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(missing), nil
+}
+
+// IsAncestor determines whether or not a Transaction is in the history of another. The empty ID is treated as an
+// ancestor of everything, and every Transaction is treated as its own ancestor.
+func IsAncestor(ctx context.Context, loader persist.Loader, ancestor, descendant envelopes.ID) (bool, error) {
+	if ancestor.Equal(envelopes.ID{}) || ancestor.Equal(descendant) {
+		return true, nil
+	}
+
+	if descendant.Equal(envelopes.ID{}) {
+		return false, nil
+	}
+
+	found := errors.New("found ancestor")
+
+	walker := persist.Walker{Loader: loader}
+	err := walker.Walk(ctx, func(_ context.Context, id envelopes.ID, _ envelopes.Transaction) error {
+		if id.Equal(ancestor) {
+			return found
+		}
+		return nil
+	}, descendant)
+	if errors.Is(err, found) {
+		return true, nil
+	}
+	return false, err
+}
+
+// Fetch copies every Transaction on a remote's branches that isn't present locally, then records where each of those
+// branches are pointing. Local branches are left alone.
+func Fetch(ctx context.Context, local loaderWriter, repoLoc string, from Remote) ([]Update, error) {
+	src, err := Open(ctx, from.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := src.ListBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make(map[string]envelopes.ID)
+	for branch := range branches {
+		heads[branch], err = src.ReadBranch(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for branch, head := range heads {
+		if _, err = Copy(ctx, src, local, head); err != nil {
+			return nil, fmt.Errorf("couldn't copy transactions from %s/%s: %w", from.Name, branch, err)
+		}
+	}
+
+	previous, err := ListTracking(ctx, repoLoc, from.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var retval []Update
+	for branch, head := range heads {
+		if old, ok := previous[branch]; ok && old.Equal(head) {
+			continue
+		}
+
+		err = WriteTracking(ctx, repoLoc, from.Name, branch, head)
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, Update{Branch: from.Name + "/" + branch, Old: previous[branch], New: head})
+	}
+
+	return retval, nil
+}
+
+// Push copies every Transaction in the history of a local branch that a remote doesn't have yet, then moves the
+// remote's branch of the same name to match. Unless force is true, the remote's branch is only moved if all of the
+// transactions it was pointing at are in the local branch's history, and if the remote isn't a working copy with that
+// branch checked out. The remote is locked while its branch is checked
+// and moved, so that it can't be changed by another process in between.
+func Push(ctx context.Context, local persist.BareRepositoryReader, repoLoc string, to Remote, branch string, force bool) (_ Update, err error) {
+	head, err := local.ReadBranch(ctx, branch)
+	if err != nil {
+		return Update{}, ErrNoBranch(branch)
+	}
+
+	destLoc, err := metadataDir(to.Location)
+	if err != nil {
+		return Update{}, err
+	}
+
+	held, err := lock.TryAcquire(destLoc)
+	if err != nil {
+		return Update{}, fmt.Errorf("couldn't push to %q: %w", to.Name, err)
+	}
+	defer func() {
+		if releaseErr := held.Release(); err == nil {
+			err = releaseErr
+		}
+	}()
+
+	dest, err := pack.OpenRepositoryWithCache(ctx, destLoc, 10000)
+	if err != nil {
+		return Update{}, err
+	}
+
+	old, err := dest.ReadBranch(ctx, branch)
+	if err != nil {
+		old = envelopes.ID{}
+	}
+
+	if !force {
+		// Like git's denyCurrentBranch, the branch a working copy has checked out isn't moved out from under its index.
+		// Only a working copy keeps its repository in a directory named for it; a bare repository has no index.
+		if filepath.Base(destLoc) == index.RepoName && !old.Equal(head) {
+			if current, err := dest.Current(ctx); err == nil && string(current) == branch {
+				return Update{}, ErrCheckedOut(branch)
+			}
+		}
+
+		// The local branch can't include the remote's history if the remote's head has never been seen here.
+		var existing envelopes.Transaction
+		if !old.Equal(envelopes.ID{}) && local.LoadTransaction(ctx, old, &existing) != nil {
+			return Update{}, ErrNonFastForward(branch)
+		}
+
+		var ok bool
+		ok, err = IsAncestor(ctx, local, old, head)
+		if err != nil {
+			return Update{}, err
+		}
+
+		if !ok {
+			return Update{}, ErrNonFastForward(branch)
+		}
+	}
+
+	_, err = Copy(ctx, local, dest, head)
+	if err != nil {
+		return Update{}, err
+	}
+
+	err = dest.WriteBranch(ctx, branch, head)
+	if err != nil {
+		return Update{}, err
+	}
+
+	err = WriteTracking(ctx, repoLoc, to.Name, branch, head)
+	if err != nil {
+		return Update{}, err
+	}
+
+	return Update{Branch: to.Name + "/" + branch, Old: old, New: head}, nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package remote
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+)
+
+func trackingDir(repoLoc string, remote string) string {
+	return filepath.Join(repoLoc, "refs", "remotes", remote)
+}
+
+// ReadTracking finds where a branch on a remote was pointing the last time it was fetched or pushed to.
+func ReadTracking(_ context.Context, repoLoc string, remote, branch string) (envelopes.ID, error) {
+	var retval envelopes.ID
+
+	contents, err := os.ReadFile(filepath.Join(trackingDir(repoLoc, remote), filepath.FromSlash(branch)))
+	if err != nil {
+		return envelopes.ID{}, err
+	}
+
+	err = retval.UnmarshalText([]byte(strings.TrimSpace(string(contents))))
+	return retval, err
+}
+
+// WriteTracking records where a branch on a remote is pointing.
+func WriteTracking(_ context.Context, repoLoc string, remote, branch string, id envelopes.ID) error {
+	const filePermissions = 0660
+	const dirPermissions = 0750
+
+	loc := filepath.Join(trackingDir(repoLoc, remote), filepath.FromSlash(branch))
+	err := os.MkdirAll(filepath.Dir(loc), dirPermissions)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(loc, []byte(id.String()), filePermissions)
+}
+
+// ListTracking finds where every branch on a remote was pointing the last time it was fetched or pushed to.
+func ListTracking(ctx context.Context, repoLoc string, remote string) (map[string]envelopes.ID, error) {
+	retval := make(map[string]envelopes.ID)
+	root := trackingDir(repoLoc, remote)
+
+	err := filepath.WalkDir(root, func(loc string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && loc == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		branch, err := filepath.Rel(root, loc)
+		if err != nil {
+			return err
+		}
+		branch = filepath.ToSlash(branch)
+
+		retval[branch], err = ReadTracking(ctx, repoLoc, remote, branch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retval, nil
+}
+
+// TrackingReader makes the branches of remotes available to be resolved like local branches, by prefixing them with
+// the name of the remote. For example, after fetching from "origin", "origin/master" refers to the master branch
+// as it was on that remote. Local branches with the same name take precedence.
+type TrackingReader struct {
+	persist.RepositoryReader
+	RepoLoc string
+}
+
+// ReadBranch fetches the ID that a local branch, or a remote's branch, is pointing at.
+func (tr TrackingReader) ReadBranch(ctx context.Context, name string) (envelopes.ID, error) {
+	retval, err := tr.RepositoryReader.ReadBranch(ctx, name)
+	if err == nil {
+		return retval, nil
+	}
+
+	remote, branch, found := strings.Cut(name, "/")
+	if !found {
+		return envelopes.ID{}, err
+	}
+
+	if tracked, trackingErr := ReadTracking(ctx, tr.RepoLoc, remote, branch); trackingErr == nil {
+		return tracked, nil
+	}
+	return envelopes.ID{}, err
+}
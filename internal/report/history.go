@@ -0,0 +1,179 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+
+	"github.com/marstr/baronial/internal/index"
+)
+
+// Point is the balance of a set of accounts and budgets as of a single Transaction.
+type Point struct {
+	Time     time.Time
+	ID       envelopes.ID
+	Label    string
+	Balances map[string]envelopes.Balance
+}
+
+// ErrUnknownEntity is returned when a balance is requested for something that is neither an account nor a budget.
+type ErrUnknownEntity string
+
+func (e ErrUnknownEntity) Error() string {
+	return fmt.Sprintf("%q was recognized as neither a budget nor an account", string(e))
+}
+
+// History finds the recursive balance of each entity as of every Transaction in the history of head whose
+// EffectiveTime falls between from and to, inclusive. Points are returned in chronological order.
+//
+// Only the first parent of each Transaction is followed. The States along a merged branch describe that branch
+// rather than head's, so interleaving them would make balances jump back and forth between the two lines of history.
+// The merge itself carries the combined balances.
+//
+// When period is empty, there is one Point per Transaction. Otherwise, there is one Point per Period, showing the
+// balances as of the last Transaction in it. When value is not nil, each balance is passed through it as of the time of
+// its Transaction.
+func History(ctx context.Context, loader persist.Loader, head envelopes.ID, from, to time.Time, period Period, entities []string, value Valuation) ([]Point, error) {
+	var retval []Point
+
+	visit := func(id envelopes.ID, transaction envelopes.Transaction) error {
+		when := EffectiveTime(transaction)
+		if when.Before(from) || when.After(to) {
+			return nil
+		}
+
+		current := Point{
+			Time:     when,
+			ID:       id,
+			Balances: make(map[string]envelopes.Balance, len(entities)),
+		}
+
+		if period == "" {
+			current.Label = when.Format("2006-01-02 15:04:05")
+		} else {
+			current.Label = period.Label(when)
+		}
+
+		for _, entity := range entities {
+			bal, err := EntityBalance(transaction.State, entity)
+			if err != nil {
+				return err
+			}
+
+			if value != nil {
+				bal, err = value(bal, when)
+				if err != nil {
+					return err
+				}
+			}
+			current.Balances[entity] = bal
+		}
+
+		retval = append(retval, current)
+		return nil
+	}
+
+	for id := head; !id.Equal(envelopes.ID{}); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var transaction envelopes.Transaction
+		err := loader.LoadTransaction(ctx, id, &transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		err = visit(id, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(transaction.Parents) == 0 {
+			break
+		}
+		id = transaction.Parents[0]
+	}
+
+	sort.SliceStable(retval, func(i, j int) bool {
+		return retval[i].Time.Before(retval[j].Time)
+	})
+
+	if period == "" {
+		return retval, nil
+	}
+
+	// Only the last Point in each Period is interesting.
+	pared := retval[:0]
+	for i := range retval {
+		if i+1 < len(retval) && retval[i+1].Label == retval[i].Label {
+			continue
+		}
+		pared = append(pared, retval[i])
+	}
+	return pared, nil
+}
+
+// EntityBalance finds the recursive balance of an account or budget in a State. Entities are named relative to the
+// root of the index, for example "accounts", "accounts/checking", or "budget/groceries". Entities that don't exist
+// in the State have an empty balance.
+func EntityBalance(state *envelopes.State, entity string) (envelopes.Balance, error) {
+	entity = strings.Trim(strings.TrimPrefix(entity, "./"), "/")
+	dir, name, _ := strings.Cut(entity, "/")
+
+	var retval envelopes.Balance
+	if state == nil {
+		state = &envelopes.State{}
+	}
+
+	switch dir {
+	case index.AccountsDir:
+		for accName, bal := range state.Accounts {
+			if name == "" || accName == name || strings.HasPrefix(accName, name+"/") {
+				retval = retval.Add(bal)
+			}
+		}
+	case index.BudgetDir:
+		current := state.Budget
+		if name != "" {
+			for _, segment := range strings.Split(name, "/") {
+				if current == nil {
+					break
+				}
+				current = current.Children[segment]
+			}
+		}
+		if current != nil {
+			retval = current.RecursiveBalance()
+		}
+	default:
+		return nil, ErrUnknownEntity(entity)
+	}
+
+	if retval == nil {
+		retval = envelopes.Balance{}
+	}
+	return retval, nil
+}
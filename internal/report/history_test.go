@@ -0,0 +1,132 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestHistory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	loader := memoryLoader{}
+	first := loader.commit(envelopes.Transaction{State: state(3000, 1000, 2000), PostedTime: day(time.January, 1)})
+	second := loader.commit(envelopes.Transaction{State: state(1500, 1000, 500), PostedTime: day(time.January, 2), Parents: []envelopes.ID{first}})
+	third := loader.commit(envelopes.Transaction{State: state(1400, 900, 500), PostedTime: day(time.February, 20), Parents: []envelopes.ID{second}})
+
+	entities := []string{"accounts", "budget/rent"}
+
+	got, err := History(ctx, loader, third, day(time.January, 1), day(time.December, 31), "", entities, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []struct {
+		id       envelopes.ID
+		accounts envelopes.Balance
+		rent     envelopes.Balance
+	}{
+		{first, usd(3000), usd(2000)},
+		{second, usd(1500), usd(500)},
+		{third, usd(1400), usd(500)},
+	}
+
+	if len(got) != len(expected) {
+		t.Logf("got %d points, want %d", len(got), len(expected))
+		t.FailNow()
+	}
+
+	for i := range expected {
+		if !got[i].ID.Equal(expected[i].id) || !got[i].Balances["accounts"].Equal(expected[i].accounts) || !got[i].Balances["budget/rent"].Equal(expected[i].rent) {
+			t.Logf("point %d\n\tgot:  %s %v\n\twant: %s %v %v", i, got[i].ID, got[i].Balances, expected[i].id, expected[i].accounts, expected[i].rent)
+			t.Fail()
+		}
+	}
+
+	monthly, err := History(ctx, loader, third, day(time.January, 1), day(time.December, 31), Month, entities, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(monthly) != 2 || !monthly[0].ID.Equal(second) || monthly[0].Label != "2026-01" || !monthly[1].ID.Equal(third) {
+		t.Logf("unexpected monthly points: %+v", monthly)
+		t.Fail()
+	}
+}
+
+func TestHistory_mergeFollowsFirstParent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	day := func(d int) time.Time {
+		return time.Date(2026, time.March, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	loader := memoryLoader{}
+	base := loader.commit(envelopes.Transaction{State: state(1000, 0, 0), PostedTime: day(1)})
+	ours := loader.commit(envelopes.Transaction{State: state(900, 0, 0), PostedTime: day(3), Parents: []envelopes.ID{base}})
+	theirs := loader.commit(envelopes.Transaction{State: state(1200, 0, 0), PostedTime: day(2), Parents: []envelopes.ID{base}})
+	merged := loader.commit(envelopes.Transaction{State: state(1100, 0, 0), PostedTime: day(4), Parents: []envelopes.ID{ours, theirs}})
+
+	got, err := History(ctx, loader, merged, day(1), day(31), "", []string{"accounts"}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []envelopes.ID{base, ours, merged}
+	if len(got) != len(expected) {
+		t.Logf("got %d points, want %d: %+v", len(got), len(expected), got)
+		t.FailNow()
+	}
+	for i := range expected {
+		if !got[i].ID.Equal(expected[i]) {
+			t.Logf("point %d\n\tgot:  %s\n\twant: %s", i, got[i].ID, expected[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestEntityBalance(t *testing.T) {
+	subject := state(1400, 900, 500)
+	subject.Accounts["bank/savings"] = usd(100)
+
+	testCases := []struct {
+		entity   string
+		expected envelopes.Balance
+	}{
+		{"accounts", usd(1500)},
+		{"accounts/bank", usd(100)},
+		{"./accounts/checking/", usd(1400)},
+		{"budget", usd(1400)},
+		{"budget/groceries", usd(900)},
+		{"budget/missing", envelopes.Balance{}},
+	}
+
+	for _, tc := range testCases {
+		got, err := EntityBalance(subject, tc.entity)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !got.Equal(tc.expected) {
+			t.Logf("%s\n\tgot:  %v\n\twant: %v", tc.entity, got, tc.expected)
+			t.Fail()
+		}
+	}
+
+	if _, err := EntityBalance(subject, "elsewhere"); err == nil {
+		t.Log("expected an error for an unknown entity")
+		t.Fail()
+	}
+}
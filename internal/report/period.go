@@ -0,0 +1,75 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package report summarizes the history of a repository, grouping changes by budget or account and by period of time.
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period is a span of calendar time that a report groups transactions into.
+type Period string
+
+// These are the Period values that are understood by ParsePeriod.
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+	Year  Period = "year"
+)
+
+// ErrUnknownPeriod is returned when a Period is requested that isn't supported.
+type ErrUnknownPeriod string
+
+func (e ErrUnknownPeriod) Error() string {
+	return fmt.Sprintf("unrecognized period %q (expected one of: %s, %s, %s, %s)", string(e), Day, Week, Month, Year)
+}
+
+// ParsePeriod interprets the name of a Period.
+func ParsePeriod(raw string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "day", "daily":
+		return Day, nil
+	case "week", "weekly":
+		return Week, nil
+	case "month", "monthly":
+		return Month, nil
+	case "year", "yearly", "annually":
+		return Year, nil
+	default:
+		return "", ErrUnknownPeriod(raw)
+	}
+}
+
+// Label names the Period that a point in time falls into. Labels sort in chronological order, for example "2026-10"
+// for a month, or "2026-W42" for an ISO week.
+func (p Period) Label(when time.Time) string {
+	switch p {
+	case Day:
+		return when.Format("2006-01-02")
+	case Week:
+		year, week := when.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case Year:
+		return when.Format("2006")
+	default:
+		return when.Format("2006-01")
+	}
+}
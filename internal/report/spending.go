@@ -0,0 +1,124 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package report
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/marstr/envelopes/persist"
+
+	"github.com/marstr/baronial/internal/format"
+)
+
+// EffectiveTime picks the time that best describes when a Transaction happened for the purposes of a report: when it
+// posted, falling back to when it actually happened, and finally to when it was entered.
+func EffectiveTime(transaction envelopes.Transaction) time.Time {
+	if !transaction.PostedTime.Equal(time.Time{}) {
+		return transaction.PostedTime
+	}
+	if !transaction.ActualTime.Equal(time.Time{}) {
+		return transaction.ActualTime
+	}
+	return transaction.EnteredTime
+}
+
+// Valuation re-expresses a Balance as of a point in time, for example by converting all of its assets into a single
+// currency.
+type Valuation func(subject envelopes.Balance, when time.Time) (envelopes.Balance, error)
+
+// Spending walks the history of a Transaction, and sums the money that left each budget in each Period between from
+// and to, inclusive. Rows are keyed by the slash separated path of the budget, as returned by format.FlattenBudgets.
+//
+// Only transactions that changed the balance of an account are considered spending; moving money between budgets is
+// not. Transactions that have been reverted, along with the transactions that reverted them, are left out entirely.
+// When value is not nil, each outflow is passed through it as of the time of its Transaction.
+func Spending(ctx context.Context, loader persist.Loader, head envelopes.ID, from, to time.Time, period Period, value Valuation) (*Table, error) {
+	type candidate struct {
+		id          envelopes.ID
+		transaction envelopes.Transaction
+	}
+
+	var candidates []candidate
+	reverted := make(map[envelopes.ID]struct{})
+
+	walker := persist.Walker{Loader: loader}
+	err := walker.Walk(ctx, func(_ context.Context, id envelopes.ID, transaction envelopes.Transaction) error {
+		for _, target := range transaction.Reverts {
+			reverted[target] = struct{}{}
+		}
+
+		when := EffectiveTime(transaction)
+		if when.Before(from) || when.After(to) {
+			return nil
+		}
+
+		candidates = append(candidates, candidate{id: id, transaction: transaction})
+		return nil
+	}, head)
+	if err != nil {
+		return nil, err
+	}
+
+	retval := NewTable()
+	for _, entry := range candidates {
+		if _, ok := reverted[entry.id]; ok || len(entry.transaction.Reverts) > 0 {
+			continue
+		}
+
+		impact, err := persist.LoadImpact(ctx, loader, entry.transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		var accountsDelta envelopes.Balance
+		for _, delta := range impact.Accounts {
+			accountsDelta = accountsDelta.Add(delta)
+		}
+		if accountsDelta.Equal(envelopes.Balance{}) {
+			continue
+		}
+
+		when := EffectiveTime(entry.transaction)
+		column := period.Label(when)
+		for budget, delta := range format.FlattenBudgets(impact) {
+			outflow := make(envelopes.Balance)
+			for asset, magnitude := range delta {
+				if magnitude.Sign() < 0 {
+					outflow[asset] = new(big.Rat).Neg(magnitude)
+				}
+			}
+
+			if len(outflow) == 0 {
+				continue
+			}
+
+			if value != nil {
+				outflow, err = value(outflow, when)
+				if err != nil {
+					return nil, err
+				}
+			}
+			retval.Add(budget, column, outflow)
+		}
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+type memoryLoader map[envelopes.ID]envelopes.Transaction
+
+func (m memoryLoader) LoadTransaction(_ context.Context, id envelopes.ID, destination *envelopes.Transaction) error {
+	found, ok := m[id]
+	if !ok {
+		return errors.New("transaction not found")
+	}
+	*destination = found
+	return nil
+}
+
+func (m memoryLoader) LoadState(context.Context, envelopes.ID, *envelopes.State) error {
+	return errors.New("not implemented")
+}
+
+func (m memoryLoader) LoadBudget(context.Context, envelopes.ID, *envelopes.Budget) error {
+	return errors.New("not implemented")
+}
+
+func (m memoryLoader) LoadAccounts(context.Context, envelopes.ID, *envelopes.Accounts) error {
+	return errors.New("not implemented")
+}
+
+// commit adds a Transaction to the loader, and returns its ID.
+func (m memoryLoader) commit(transaction envelopes.Transaction) envelopes.ID {
+	id := transaction.ID()
+	m[id] = transaction
+	return id
+}
+
+func usd(dollars int64) envelopes.Balance {
+	return envelopes.Balance{"USD": big.NewRat(dollars, 1)}
+}
+
+func state(checking, groceries, rent int64) *envelopes.State {
+	return &envelopes.State{
+		Accounts: envelopes.Accounts{"checking": usd(checking)},
+		Budget: &envelopes.Budget{
+			Children: map[string]*envelopes.Budget{
+				"groceries": {Balance: usd(groceries)},
+				"rent":      {Balance: usd(rent)},
+			},
+		},
+	}
+}
+
+func TestSpending(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	loader := memoryLoader{}
+	paycheck := loader.commit(envelopes.Transaction{State: state(3000, 1000, 2000), PostedTime: day(time.January, 1)})
+	rent := loader.commit(envelopes.Transaction{State: state(1500, 1000, 500), PostedTime: day(time.January, 2), Parents: []envelopes.ID{paycheck}})
+	food := loader.commit(envelopes.Transaction{State: state(1400, 900, 500), PostedTime: day(time.January, 20), Parents: []envelopes.ID{rent}})
+	transfer := loader.commit(envelopes.Transaction{State: state(1400, 800, 600), PostedTime: day(time.February, 1), Parents: []envelopes.ID{food}})
+	mistake := loader.commit(envelopes.Transaction{State: state(1350, 750, 600), PostedTime: day(time.February, 3), Parents: []envelopes.ID{transfer}})
+	undo := loader.commit(envelopes.Transaction{State: state(1400, 800, 600), PostedTime: day(time.February, 4), Parents: []envelopes.ID{mistake}, Reverts: []envelopes.ID{mistake}})
+	moreFood := loader.commit(envelopes.Transaction{State: state(1375, 775, 600), PostedTime: day(time.February, 10), Parents: []envelopes.ID{undo}})
+	late := loader.commit(envelopes.Transaction{State: state(1300, 700, 600), PostedTime: day(time.March, 10), Parents: []envelopes.ID{moreFood}})
+
+	got, err := Spending(ctx, loader, late, day(time.January, 1), day(time.February, 28), Month, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := map[string]map[string]envelopes.Balance{
+		"rent":      {"2026-01": usd(1500)},
+		"groceries": {"2026-01": usd(100), "2026-02": usd(25)},
+	}
+
+	if rows := got.Rows(); len(rows) != len(expected) {
+		t.Logf("got rows %v, want %d rows", rows, len(expected))
+		t.Fail()
+	}
+
+	for row, columns := range expected {
+		for column, want := range columns {
+			if cell := got.Get(row, column); !cell.Equal(want) {
+				t.Logf("%s %s\n\tgot:  %v\n\twant: %v", row, column, cell, want)
+				t.Fail()
+			}
+		}
+	}
+
+	if total := got.Total(); !total.Equal(usd(1625)) {
+		t.Logf("total\n\tgot:  %v\n\twant: %v", total, usd(1625))
+		t.Fail()
+	}
+}
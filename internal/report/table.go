@@ -0,0 +1,201 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/marstr/envelopes"
+
+	"github.com/marstr/baronial/internal/format"
+)
+
+// Table accumulates balances by row, for example a budget, and column, for example a Period label.
+type Table struct {
+	cells map[string]map[string]envelopes.Balance
+}
+
+// NewTable creates an empty Table.
+func NewTable() *Table {
+	return &Table{cells: make(map[string]map[string]envelopes.Balance)}
+}
+
+// Add sums an amount into a single cell of the Table.
+func (t *Table) Add(row, column string, amount envelopes.Balance) {
+	if _, ok := t.cells[row]; !ok {
+		t.cells[row] = make(map[string]envelopes.Balance)
+	}
+	t.cells[row][column] = t.cells[row][column].Add(amount)
+}
+
+// Get fetches the amount in a single cell of the Table.
+func (t *Table) Get(row, column string) envelopes.Balance {
+	return t.cells[row][column]
+}
+
+// Rows lists the name of each row in the Table, in sorted order.
+func (t *Table) Rows() []string {
+	retval := make([]string, 0, len(t.cells))
+	for row := range t.cells {
+		retval = append(retval, row)
+	}
+	sort.Strings(retval)
+	return retval
+}
+
+// Columns lists the name of each column that has at least one cell in the Table, in sorted order.
+func (t *Table) Columns() []string {
+	seen := make(map[string]struct{})
+	for _, row := range t.cells {
+		for column := range row {
+			seen[column] = struct{}{}
+		}
+	}
+
+	retval := make([]string, 0, len(seen))
+	for column := range seen {
+		retval = append(retval, column)
+	}
+	sort.Strings(retval)
+	return retval
+}
+
+// RowTotal sums every cell in a row.
+func (t *Table) RowTotal(row string) envelopes.Balance {
+	var retval envelopes.Balance
+	for _, amount := range t.cells[row] {
+		retval = retval.Add(amount)
+	}
+	return retval
+}
+
+// ColumnTotal sums every cell in a column.
+func (t *Table) ColumnTotal(column string) envelopes.Balance {
+	var retval envelopes.Balance
+	for _, row := range t.cells {
+		retval = retval.Add(row[column])
+	}
+	return retval
+}
+
+// Total sums every cell in the Table.
+func (t *Table) Total() envelopes.Balance {
+	var retval envelopes.Balance
+	for row := range t.cells {
+		retval = retval.Add(t.RowTotal(row))
+	}
+	return retval
+}
+
+// WriteText prints the Table with aligned columns. When totals is set, an extra column and row are added that sum up
+// each row and column.
+func (t *Table) WriteText(output io.Writer, corner string, totals bool) error {
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	columns := t.Columns()
+
+	line := corner + "\t"
+	for _, column := range columns {
+		line += column + "\t"
+	}
+	if totals {
+		line += "Total\t"
+	}
+	if _, err := fmt.Fprintln(writer, line); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows() {
+		line = row + "\t"
+		for _, column := range columns {
+			line += formatCell(t.Get(row, column)) + "\t"
+		}
+		if totals {
+			line += formatCell(t.RowTotal(row)) + "\t"
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+
+	if totals {
+		line = "Total\t"
+		for _, column := range columns {
+			line += formatCell(t.ColumnTotal(column)) + "\t"
+		}
+		line += formatCell(t.Total()) + "\t"
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func formatCell(amount envelopes.Balance) string {
+	if amount.Equal(envelopes.Balance{}) {
+		return "-"
+	}
+	return amount.String()
+}
+
+// TableDocument is the structured form of a Table.
+type TableDocument struct {
+	Columns []string                          `json:"columns" yaml:"columns"`
+	Rows    map[string]RowDocument            `json:"rows" yaml:"rows"`
+	Totals  map[string]format.BalanceDocument `json:"totals,omitempty" yaml:"totals,omitempty"`
+	Total   format.BalanceDocument            `json:"total,omitempty" yaml:"total,omitempty"`
+}
+
+// RowDocument is the structured form of a single row of a Table.
+type RowDocument struct {
+	Cells map[string]format.BalanceDocument `json:"cells" yaml:"cells"`
+	Total format.BalanceDocument            `json:"total,omitempty" yaml:"total,omitempty"`
+}
+
+// NewTableDocument converts a Table into its structured form. When totals is set, the sums of each row and column
+// are included.
+func NewTableDocument(subject *Table, totals bool) TableDocument {
+	retval := TableDocument{
+		Columns: subject.Columns(),
+		Rows:    make(map[string]RowDocument, len(subject.cells)),
+	}
+
+	for row, cells := range subject.cells {
+		entry := RowDocument{Cells: make(map[string]format.BalanceDocument, len(cells))}
+		for column, amount := range cells {
+			entry.Cells[column] = format.NewBalanceDocument(amount)
+		}
+		if totals {
+			entry.Total = format.NewBalanceDocument(subject.RowTotal(row))
+		}
+		retval.Rows[row] = entry
+	}
+
+	if totals {
+		retval.Totals = make(map[string]format.BalanceDocument, len(retval.Columns))
+		for _, column := range retval.Columns {
+			retval.Totals[column] = format.NewBalanceDocument(subject.ColumnTotal(column))
+		}
+		retval.Total = format.NewBalanceDocument(subject.Total())
+	}
+
+	return retval
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit is the granularity that a Recurrence repeats at.
+type Unit string
+
+// These are the Units that a Recurrence can be expressed in.
+const (
+	Day   Unit = "day"
+	Week  Unit = "week"
+	Month Unit = "month"
+	Year  Unit = "year"
+)
+
+// Recurrence describes how often a Template should be committed, for instance "every 2 weeks".
+type Recurrence struct {
+	Interval uint
+	Unit     Unit
+}
+
+// ErrBadRecurrence is returned when text can't be interpreted as a Recurrence.
+type ErrBadRecurrence string
+
+func (e ErrBadRecurrence) Error() string {
+	return fmt.Sprintf("%q is not a recognized recurrence (try \"monthly\", or \"every 2 weeks\")", string(e))
+}
+
+var recurrenceAliases = map[string]Recurrence{
+	"daily":       {1, Day},
+	"weekly":      {1, Week},
+	"biweekly":    {2, Week},
+	"fortnightly": {2, Week},
+	"monthly":     {1, Month},
+	"quarterly":   {3, Month},
+	"yearly":      {1, Year},
+	"annually":    {1, Year},
+}
+
+// ParseRecurrence interprets text like "monthly", "every 2 weeks", or "10 days" as a Recurrence.
+func ParseRecurrence(raw string) (Recurrence, error) {
+	cleaned := strings.ToLower(strings.TrimSpace(raw))
+	if alias, ok := recurrenceAliases[cleaned]; ok {
+		return alias, nil
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(cleaned, "every "))
+
+	var interval uint64 = 1
+	switch len(fields) {
+	case 1:
+		// Intentionally Left Blank
+	case 2:
+		var err error
+		interval, err = strconv.ParseUint(fields[0], 10, 32)
+		if err != nil || interval == 0 {
+			return Recurrence{}, ErrBadRecurrence(raw)
+		}
+		fields = fields[1:]
+	default:
+		return Recurrence{}, ErrBadRecurrence(raw)
+	}
+
+	unit := Unit(strings.TrimSuffix(fields[0], "s"))
+	switch unit {
+	case Day, Week, Month, Year:
+		return Recurrence{Interval: uint(interval), Unit: unit}, nil
+	default:
+		return Recurrence{}, ErrBadRecurrence(raw)
+	}
+}
+
+// String presents a Recurrence in a form that ParseRecurrence understands.
+func (r Recurrence) String() string {
+	switch r {
+	case Recurrence{1, Day}:
+		return "daily"
+	case Recurrence{1, Week}:
+		return "weekly"
+	case Recurrence{2, Week}:
+		return "biweekly"
+	case Recurrence{1, Month}:
+		return "monthly"
+	case Recurrence{3, Month}:
+		return "quarterly"
+	case Recurrence{1, Year}:
+		return "yearly"
+	default:
+		return fmt.Sprintf("every %d %ss", r.Interval, r.Unit)
+	}
+}
+
+// MarshalText allows a Recurrence to be stored in human-readable form.
+func (r Recurrence) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads a Recurrence that was written by MarshalText.
+func (r *Recurrence) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseRecurrence(string(text))
+	return
+}
+
+// Occurrence finds the nth time, counting from zero, that an event which first happened at start should recur.
+//
+// Monthly and yearly recurrences that fall on a day that doesn't exist in a particular month are moved to the last
+// day of that month, rather than spilling into the next one. For instance, a monthly recurrence starting on January 31
+// will next occur on the last day of February.
+func (r Recurrence) Occurrence(start time.Time, n uint) time.Time {
+	steps := int(r.Interval * n)
+	switch r.Unit {
+	case Day:
+		return start.AddDate(0, 0, steps)
+	case Week:
+		return start.AddDate(0, 0, 7*steps)
+	case Month:
+		return addMonthsClamped(start, steps)
+	case Year:
+		return addMonthsClamped(start, 12*steps)
+	default:
+		return start
+	}
+}
+
+func addMonthsClamped(start time.Time, months int) time.Time {
+	year, month, day := start.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfTarget.AddDate(0, 0, day-1)
+}
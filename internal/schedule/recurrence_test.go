@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected Recurrence
+	}{
+		{"monthly", Recurrence{1, Month}},
+		{"Biweekly", Recurrence{2, Week}},
+		{"every 2 weeks", Recurrence{2, Week}},
+		{"10 days", Recurrence{10, Day}},
+		{"every year", Recurrence{1, Year}},
+		{"quarterly", Recurrence{3, Month}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			got, err := ParseRecurrence(tc.raw)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if got != tc.expected {
+				t.Logf("got: %v want: %v", got, tc.expected)
+				t.Fail()
+			}
+
+			roundTripped, err := ParseRecurrence(got.String())
+			if err != nil {
+				t.Error(err)
+			} else if roundTripped != got {
+				t.Logf("%q did not round trip, got: %v", got.String(), roundTripped)
+				t.Fail()
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "sometimes", "every 0 days", "every -1 weeks", "2 fortnights"} {
+		if _, err := ParseRecurrence(bad); err == nil {
+			t.Logf("expected an error for %q", bad)
+			t.Fail()
+		}
+	}
+}
+
+func TestRecurrence_Occurrence(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		recurrence Recurrence
+		n          uint
+		expected   time.Time
+	}{
+		{Recurrence{1, Month}, 0, start},
+		{Recurrence{1, Month}, 1, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)},
+		{Recurrence{1, Month}, 2, time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		{Recurrence{2, Week}, 3, time.Date(2024, time.March, 13, 9, 0, 0, 0, time.UTC)},
+		{Recurrence{1, Year}, 1, time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC)},
+		{Recurrence{3, Day}, 2, time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.recurrence.Occurrence(start, tc.n); !got.Equal(tc.expected) {
+			t.Logf("%v #%d\n\tgot:  %v\n\twant: %v", tc.recurrence, tc.n, got, tc.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestDueAll(t *testing.T) {
+	end := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	templates := []Template{
+		{
+			Name:       "rent",
+			Recurrence: Recurrence{1, Month},
+			Start:      time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Committed:  1,
+		},
+		{
+			Name:       "paycheck",
+			Recurrence: Recurrence{2, Week},
+			Start:      time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC),
+			End:        &end,
+		},
+	}
+
+	got := DueAll(templates, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC))
+
+	expected := []struct {
+		name string
+		when time.Time
+	}{
+		{"paycheck", time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{"paycheck", time.Date(2024, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"rent", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"paycheck", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"paycheck", time.Date(2024, time.February, 16, 0, 0, 0, 0, time.UTC)},
+		{"paycheck", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"rent", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"rent", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	if len(got) != len(expected) {
+		t.Logf("got %d occurrences, want %d", len(got), len(expected))
+		t.FailNow()
+	}
+
+	for i := range got {
+		if got[i].Template.Name != expected[i].name || !got[i].When.Equal(expected[i].when) {
+			t.Logf("occurrence %d\n\tgot:  %s %v\n\twant: %s %v", i, got[i].Template.Name, got[i].When, expected[i].name, expected[i].when)
+			t.Fail()
+		}
+	}
+}
+
+func TestWrite_keepsOccurrences(t *testing.T) {
+	ctx := context.Background()
+	repoLoc := t.TempDir()
+
+	templates := []Template{
+		{Name: "rent", Recurrence: Recurrence{1, Month}, Start: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "paycheck", Recurrence: Recurrence{2, Week}, Start: time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	due := DueAll(templates, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC))
+	for _, occurrence := range due {
+		occurrence.Template.Committed = occurrence.Index + 1
+		err := Write(ctx, repoLoc, templates)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	got, err := Load(ctx, repoLoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := map[string]uint{"paycheck": 2, "rent": 1}
+	for _, template := range got {
+		if template.Committed != expected[template.Name] {
+			t.Logf("%s\n\tgot:  %d committed\n\twant: %d committed", template.Name, template.Committed, expected[template.Name])
+			t.Fail()
+		}
+	}
+}
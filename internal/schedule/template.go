@@ -0,0 +1,145 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package schedule describes transactions that happen over and over again, like rent or a paycheck, so that they can
+// be committed without being entered by hand each time.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// Filename is the name of the file, relative to the root of a repository, where Templates are stored.
+const Filename = "schedule.json"
+
+// Template captures the details of a transaction that is committed on a recurring basis.
+type Template struct {
+	Name       string     `json:"name"`
+	Recurrence Recurrence `json:"recurrence"`
+	Start      time.Time  `json:"start"`
+	End        *time.Time `json:"end,omitempty"`
+
+	Merchant string            `json:"merchant,omitempty"`
+	Comment  string            `json:"comment,omitempty"`
+	Amount   envelopes.Balance `json:"amount,omitempty"`
+
+	// Impacts maps the names of accounts and budgets, as they appear in the index (e.g. "accounts/checking" or
+	// "budget/housing/rent"), to how much they should change each time this Template is committed.
+	Impacts map[string]envelopes.Balance `json:"impacts"`
+
+	// Committed is the number of occurrences of this Template that have already been committed.
+	Committed uint `json:"committed"`
+}
+
+// Occurrence is a single instance of a Template that should be committed.
+type Occurrence struct {
+	Template *Template
+	Index    uint
+	When     time.Time
+}
+
+// Due finds each occurrence of a Template that hasn't been committed yet, and is scheduled at or before until.
+func (t *Template) Due(until time.Time) []Occurrence {
+	var retval []Occurrence
+	for i := t.Committed; ; i++ {
+		when := t.Recurrence.Occurrence(t.Start, i)
+		if when.After(until) || (t.End != nil && when.After(*t.End)) {
+			break
+		}
+		retval = append(retval, Occurrence{Template: t, Index: i, When: when})
+	}
+	return retval
+}
+
+// Next finds when the next uncommitted occurrence of a Template is scheduled. If the Template has ended, false is
+// returned.
+func (t *Template) Next() (time.Time, bool) {
+	when := t.Recurrence.Occurrence(t.Start, t.Committed)
+	if t.End != nil && when.After(*t.End) {
+		return time.Time{}, false
+	}
+	return when, true
+}
+
+// DueAll collects the Occurrences of many Templates that are due, sorted in the order they should be committed.
+func DueAll(templates []Template, until time.Time) []Occurrence {
+	var retval []Occurrence
+	for i := range templates {
+		retval = append(retval, templates[i].Due(until)...)
+	}
+
+	sort.SliceStable(retval, func(i, j int) bool {
+		if !retval[i].When.Equal(retval[j].When) {
+			return retval[i].When.Before(retval[j].When)
+		}
+		return retval[i].Template.Name < retval[j].Template.Name
+	})
+	return retval
+}
+
+// Load reads all Templates that are stored in a repository. A repository without any Templates is not an error.
+func Load(_ context.Context, repoLoc string) ([]Template, error) {
+	contents, err := os.ReadFile(filepath.Join(repoLoc, Filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var retval []Template
+	err = json.Unmarshal(contents, &retval)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the schedule json: %w", err)
+	}
+	return retval, nil
+}
+
+// Write replaces all Templates that are stored in a repository. The slice that is passed in is left in the order it
+// was given, so that pointers into it (like those held by an Occurrence) stay valid.
+func Write(_ context.Context, repoLoc string, templates []Template) error {
+	const filePermissions = 0660
+
+	templates = append([]Template(nil), templates...)
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	toWrite, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal schedule: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(repoLoc, Filename), toWrite, filePermissions)
+}
+
+// Find locates the Template with a given name, returning its position or -1 if it isn't present.
+func Find(templates []Template, name string) int {
+	for i := range templates {
+		if templates[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statement
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+	"github.com/spf13/cast"
+)
+
+// CSVMapping describes where each field of an Entry can be found in the rows of a CSV statement. Columns may be
+// identified either by the text in their header, or by their 1-based position. Columns that are left empty aren't
+// read.
+type CSVMapping struct {
+	// PostedTime is the column holding when a transaction posted. If it isn't specified, or is empty in a row, the
+	// ActualTime is used instead. At least one of them must be specified.
+	PostedTime string `json:"posted_time,omitempty"`
+	ActualTime string `json:"actual_time,omitempty"`
+	Amount     string `json:"amount"`
+	Merchant   string `json:"merchant,omitempty"`
+	RecordID   string `json:"bank_record_id,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+
+	// TimeFormat is the layout, as understood by time.Parse, of the time columns. When it is empty, a handful of
+	// common formats are attempted.
+	TimeFormat string `json:"time_format,omitempty"`
+
+	// NoHeader indicates that the first row of the statement is a transaction, rather than the names of each column.
+	NoHeader bool `json:"no_header,omitempty"`
+
+	// InvertAmount should be set for institutions which present money leaving the account as a positive number.
+	InvertAmount bool `json:"invert_amount,omitempty"`
+
+	// Asset is the type of asset amounts are denominated in when the amount column doesn't say.
+	Asset envelopes.AssetType `json:"asset,omitempty"`
+}
+
+// ErrUnknownColumn is returned when a CSVMapping refers to a column that isn't present in a statement.
+type ErrUnknownColumn string
+
+func (e ErrUnknownColumn) Error() string {
+	return fmt.Sprintf("no column %q in statement", string(e))
+}
+
+// ReadCSV finds every transaction in a CSV statement, using the provided mapping to decide which column holds each
+// field.
+func ReadCSV(ctx context.Context, input io.Reader, mapping CSVMapping) ([]Entry, error) {
+	if mapping.Amount == "" {
+		return nil, errors.New("the amount column must be specified")
+	}
+
+	if mapping.PostedTime == "" && mapping.ActualTime == "" {
+		return nil, errors.New("either the posted time or actual time column must be specified")
+	}
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var header []string
+	if !mapping.NoHeader {
+		var err error
+		header, err = reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	postedCol, err := findColumn(header, mapping.PostedTime)
+	if err != nil {
+		return nil, err
+	}
+	actualCol, err := findColumn(header, mapping.ActualTime)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, err := findColumn(header, mapping.Amount)
+	if err != nil {
+		return nil, err
+	}
+	merchantCol, err := findColumn(header, mapping.Merchant)
+	if err != nil {
+		return nil, err
+	}
+	recordCol, err := findColumn(header, mapping.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	commentCol, err := findColumn(header, mapping.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := mapping.Asset
+	if asset == "" {
+		asset = envelopes.DefaultAsset
+	}
+
+	var results []Entry
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if isBlankRow(row) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		var current Entry
+
+		if raw := cell(row, postedCol); raw != "" {
+			current.PostedTime, err = parseCSVTime(raw, mapping.TimeFormat)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		if raw := cell(row, actualCol); raw != "" {
+			current.ActualTime, err = parseCSVTime(raw, mapping.TimeFormat)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		if current.PostedTime.IsZero() {
+			if current.ActualTime.IsZero() {
+				return nil, fmt.Errorf("line %d: neither a posted nor an actual time was provided", line)
+			}
+			current.PostedTime = current.ActualTime
+		}
+
+		current.Amount, err = parseCSVAmount(cell(row, amountCol), asset)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if mapping.InvertAmount {
+			current.Amount = current.Amount.Negate()
+		}
+
+		current.Merchant = cell(row, merchantCol)
+		current.Comment = cell(row, commentCol)
+		current.RecordID = envelopes.BankRecordID(cell(row, recordCol))
+
+		results = append(results, current)
+	}
+
+	return results, nil
+}
+
+// findColumn determines the 0-based index of a column, or -1 if no column was requested.
+func findColumn(header []string, spec string) (int, error) {
+	if spec == "" {
+		return -1, nil
+	}
+
+	for i := range header {
+		if strings.EqualFold(strings.TrimSpace(header[i]), spec) {
+			return i, nil
+		}
+	}
+
+	if position, err := strconv.Atoi(spec); err == nil && position > 0 {
+		return position - 1, nil
+	}
+
+	return -1, ErrUnknownColumn(spec)
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func isBlankRow(row []string) bool {
+	for _, entry := range row {
+		if strings.TrimSpace(entry) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	csvNumber        = regexp.MustCompile(`[0-9][0-9,]*(\.[0-9]*)?`)
+	csvGroupedNumber = regexp.MustCompile(`^[0-9]{1,3}(,[0-9]{3})+(\.[0-9]*)?$`)
+)
+
+// parseCSVAmount understands the decorations banks tend to add to amounts, like currency symbols, commas between
+// groups of thousands, and accounting-style parentheses around negative numbers.
+func parseCSVAmount(raw string, asset envelopes.AssetType) (envelopes.Balance, error) {
+	cleaned := strings.TrimSpace(raw)
+	if cleaned == "" {
+		return nil, errors.New("missing amount")
+	}
+
+	negative := false
+	if strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")") {
+		negative = true
+		cleaned = strings.Trim(cleaned, "()")
+	}
+	cleaned = strings.ReplaceAll(cleaned, "$", "")
+	cleaned = strings.ReplaceAll(cleaned, " ", "")
+
+	if strings.Contains(cleaned, ",") {
+		number := csvNumber.FindString(cleaned)
+		if !csvGroupedNumber.MatchString(number) {
+			return nil, fmt.Errorf("%q not recognized as an amount, commas may only separate groups of thousands", raw)
+		}
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	parsed, err := envelopes.ParseBalanceWithDefault([]byte(cleaned), asset)
+	if err != nil {
+		return nil, fmt.Errorf("%q not recognized as an amount", raw)
+	}
+
+	if negative {
+		parsed = parsed.Negate()
+	}
+	return parsed, nil
+}
+
+func parseCSVTime(raw string, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, raw)
+	}
+
+	for _, candidate := range []string{"01/02/2006", "1/2/2006", "2006-01-02"} {
+		if parsed, err := time.Parse(candidate, raw); err == nil {
+			return parsed, nil
+		}
+	}
+
+	parsed, err := cast.ToTimeE(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse time from %q because: %v", raw, err)
+	}
+	return parsed, nil
+}
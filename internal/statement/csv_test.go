@@ -0,0 +1,173 @@
+package statement
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+func TestReadCSV(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		mapping  CSVMapping
+		expected []Entry
+	}{
+		{
+			"header names",
+			"Date,Description,Amount,Reference\n01/05/2024,GROCERY OUTLET,-42.17,A1\n\n01/15/2024,PAYROLL,\"$1,500.00\",A2\n",
+			CSVMapping{
+				PostedTime: "date",
+				Merchant:   "Description",
+				Amount:     "Amount",
+				RecordID:   "Reference",
+			},
+			[]Entry{
+				{
+					PostedTime: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"USD": big.NewRat(-4217, 100)},
+					Merchant:   "GROCERY OUTLET",
+					RecordID:   "A1",
+				},
+				{
+					PostedTime: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"USD": big.NewRat(1500, 1)},
+					Merchant:   "PAYROLL",
+					RecordID:   "A2",
+				},
+			},
+		},
+		{
+			"positions without header",
+			"2024.03.02,(9.99),STREAMING CO,monthly\n2024.03.04,20.00,REFUND,\n",
+			CSVMapping{
+				PostedTime:   "1",
+				Amount:       "2",
+				Merchant:     "3",
+				Comment:      "4",
+				TimeFormat:   "2006.01.02",
+				NoHeader:     true,
+				InvertAmount: true,
+				Asset:        "CAD",
+			},
+			[]Entry{
+				{
+					PostedTime: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"CAD": big.NewRat(999, 100)},
+					Merchant:   "STREAMING CO",
+					Comment:    "monthly",
+				},
+				{
+					PostedTime: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"CAD": big.NewRat(-20, 1)},
+					Merchant:   "REFUND",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadCSV(ctx, strings.NewReader(tc.input), tc.mapping)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Logf("got %d entries, want %d", len(got), len(tc.expected))
+				t.FailNow()
+			}
+
+			for i := range got {
+				if !entriesEqual(got[i], tc.expected[i]) {
+					t.Logf("entry %d\n\tgot:  %+v\n\twant: %+v", i, got[i], tc.expected[i])
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestReadCSV_unknownColumn(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := ReadCSV(ctx, strings.NewReader("Date,Amount\n01/05/2024,1.00\n"), CSVMapping{PostedTime: "Date", Amount: "Total"})
+	if _, ok := err.(ErrUnknownColumn); !ok {
+		t.Logf("got: %v want: ErrUnknownColumn", err)
+		t.Fail()
+	}
+}
+
+func TestReadCSV_noTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := ReadCSV(ctx, strings.NewReader("Date,Amount\n01/05/2024,1.00\n"), CSVMapping{Amount: "Amount"})
+	if err == nil {
+		t.Log("expected a mapping without any time column to be rejected")
+		t.Fail()
+	}
+
+	got, err := ReadCSV(ctx, strings.NewReader("Date,Amount\n01/05/2024,1.00\n"), CSVMapping{ActualTime: "Date", Amount: "Amount"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	if len(got) != 1 || !got[0].PostedTime.Equal(expected) || !got[0].ActualTime.Equal(expected) {
+		t.Logf("expected the posted time to fall back to the actual time, got: %+v", got)
+		t.Fail()
+	}
+
+	_, err = ReadCSV(ctx, strings.NewReader("Posted,Actual,Amount\n01/05/2024,,1.00\n,,2.00\n"), CSVMapping{PostedTime: "Posted", ActualTime: "Actual", Amount: "Amount"})
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Logf("expected a row without any time to be rejected on line 3, got: %v", err)
+		t.Fail()
+	}
+}
+
+func TestParseCSVAmount(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected envelopes.Balance
+	}{
+		{"1,234.56", envelopes.Balance{"USD": big.NewRat(123456, 100)}},
+		{"-1,234.56", envelopes.Balance{"USD": big.NewRat(-123456, 100)}},
+		{"$1,234,567", envelopes.Balance{"USD": big.NewRat(1234567, 1)}},
+		{"(2,000.10)", envelopes.Balance{"USD": big.NewRat(-200010, 100)}},
+		{"999.99", envelopes.Balance{"USD": big.NewRat(99999, 100)}},
+		{"1,5", nil},
+		{"12,34.00", nil},
+	}
+
+	for _, tc := range testCases {
+		got, err := parseCSVAmount(tc.raw, envelopes.DefaultAsset)
+		if tc.expected == nil {
+			if err == nil {
+				t.Logf("expected %q to be rejected, got: %s", tc.raw, got)
+				t.Fail()
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !got.Equal(tc.expected) {
+			t.Logf("%q\n\tgot:  %s\n\twant: %s", tc.raw, got, tc.expected)
+			t.Fail()
+		}
+	}
+}
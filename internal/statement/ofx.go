@@ -0,0 +1,168 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statement
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// ErrMalformedOFX is returned when an OFX document can't be interpreted as a bank or credit card statement.
+type ErrMalformedOFX string
+
+func (e ErrMalformedOFX) Error() string {
+	return fmt.Sprintf("malformed OFX: %s", string(e))
+}
+
+// ReadOFX finds every posted transaction in an OFX or QFX document. Both the SGML flavor (OFX 1.x, where leaf elements
+// are rarely closed) and the XML flavor (OFX 2.x) are supported.
+func ReadOFX(ctx context.Context, input io.Reader) ([]Entry, error) {
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	body := string(raw)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, ErrMalformedOFX("no <OFX> element found")
+	}
+	body = body[start:]
+
+	var results []Entry
+	var current *Entry
+	asset := envelopes.DefaultAsset
+
+	for len(body) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// Intentionally Left Blank
+		}
+
+		open := strings.IndexRune(body, '<')
+		if open < 0 {
+			break
+		}
+		closing := strings.IndexRune(body[open:], '>')
+		if closing < 0 {
+			return nil, ErrMalformedOFX("unterminated tag")
+		}
+		closing += open
+
+		tag := strings.ToUpper(strings.TrimSpace(body[open+1 : closing]))
+		body = body[closing+1:]
+
+		next := strings.IndexRune(body, '<')
+		if next < 0 {
+			next = len(body)
+		}
+		value := ofxUnescape(strings.TrimSpace(body[:next]))
+
+		switch tag {
+		case "STMTTRN":
+			current = &Entry{}
+		case "/STMTTRN":
+			if current == nil {
+				return nil, ErrMalformedOFX("</STMTTRN> without matching <STMTTRN>")
+			}
+			if current.Amount == nil {
+				return nil, ErrMalformedOFX("transaction without TRNAMT")
+			}
+			if current.Merchant == "" {
+				current.Merchant = current.Comment
+				current.Comment = ""
+			}
+			results = append(results, *current)
+			current = nil
+		case "CURDEF":
+			if value != "" {
+				asset = envelopes.AssetType(value)
+			}
+		}
+
+		if current == nil || value == "" {
+			continue
+		}
+
+		switch tag {
+		case "DTPOSTED":
+			current.PostedTime, err = parseOFXTime(value)
+		case "DTUSER":
+			current.ActualTime, err = parseOFXTime(value)
+		case "TRNAMT":
+			current.Amount, err = envelopes.ParseBalanceWithDefault([]byte(value), asset)
+		case "FITID":
+			current.RecordID = envelopes.BankRecordID(value)
+		case "NAME", "PAYEE":
+			current.Merchant = value
+		case "MEMO":
+			current.Comment = value
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if current != nil {
+		return nil, ErrMalformedOFX("<STMTTRN> was never closed")
+	}
+
+	return results, nil
+}
+
+var ofxTimePattern = regexp.MustCompile(`^(\d{8})(\d{6})?(?:\.\d+)?(?:\[([+-]?\d+(?:\.\d+)?)(?::[^\]]*)?\])?$`)
+
+// parseOFXTime interprets the OFX datetime format, "YYYYMMDDHHMMSS.XXX[gmt offset:tz name]", where everything after
+// the date is optional. Without an offset, times are in GMT.
+func parseOFXTime(raw string) (time.Time, error) {
+	match := ofxTimePattern.FindStringSubmatch(raw)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("%q is not an OFX datetime", raw)
+	}
+
+	location := time.UTC
+	if match[3] != "" {
+		hours, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		location = time.FixedZone("", int(hours*60*60))
+	}
+
+	clock := match[2]
+	if clock == "" {
+		clock = "000000"
+	}
+
+	return time.ParseInLocation("20060102150405", match[1]+clock, location)
+}
+
+var ofxEscapes = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&", "&nbsp;", " ")
+
+func ofxUnescape(raw string) string {
+	return ofxEscapes.Replace(raw)
+}
@@ -0,0 +1,154 @@
+package statement
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKTRANLIST>
+<DTSTART>20240101
+<DTEND>20240131
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240105120000.000[-5:EST]
+<TRNAMT>-42.17
+<FITID>2024010501
+<NAME>GROCERY OUTLET &amp; MORE
+<MEMO>POS PURCHASE
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240115
+<TRNAMT>1500.00
+<FITID>2024011502
+<MEMO>PAYROLL
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+	<CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+		<CURDEF>CAD</CURDEF>
+		<BANKTRANLIST>
+			<STMTTRN>
+				<TRNTYPE>DEBIT</TRNTYPE>
+				<DTPOSTED>20240302</DTPOSTED>
+				<DTUSER>20240301</DTUSER>
+				<TRNAMT>-9.99</TRNAMT>
+				<FITID>A1</FITID>
+				<NAME>STREAMING CO</NAME>
+			</STMTTRN>
+		</BANKTRANLIST>
+	</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestReadOFX(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Entry
+	}{
+		{
+			"sgml",
+			sgmlStatement,
+			[]Entry{
+				{
+					PostedTime: time.Date(2024, 1, 5, 12, 0, 0, 0, time.FixedZone("", -5*60*60)),
+					Amount:     envelopes.Balance{"USD": big.NewRat(-4217, 100)},
+					Merchant:   "GROCERY OUTLET & MORE",
+					Comment:    "POS PURCHASE",
+					RecordID:   "2024010501",
+				},
+				{
+					PostedTime: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"USD": big.NewRat(1500, 1)},
+					Merchant:   "PAYROLL",
+					RecordID:   "2024011502",
+				},
+			},
+		},
+		{
+			"xml",
+			xmlStatement,
+			[]Entry{
+				{
+					PostedTime: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+					ActualTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					Amount:     envelopes.Balance{"CAD": big.NewRat(-999, 100)},
+					Merchant:   "STREAMING CO",
+					RecordID:   "A1",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadOFX(ctx, strings.NewReader(tc.input))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Logf("got %d entries, want %d", len(got), len(tc.expected))
+				t.FailNow()
+			}
+
+			for i := range got {
+				if !entriesEqual(got[i], tc.expected[i]) {
+					t.Logf("entry %d\n\tgot:  %+v\n\twant: %+v", i, got[i], tc.expected[i])
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestReadOFX_malformed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	testCases := []string{
+		"",
+		"<OFX><STMTTRN><TRNAMT>1.00",
+		"<OFX><STMTTRN><FITID>1</STMTTRN></OFX>",
+		"<OFX><STMTTRN><DTPOSTED>yesterday<TRNAMT>1.00</STMTTRN></OFX>",
+	}
+
+	for _, tc := range testCases {
+		if _, err := ReadOFX(ctx, strings.NewReader(tc)); err == nil {
+			t.Logf("expected an error for: %q", tc)
+			t.Fail()
+		}
+	}
+}
+
+func entriesEqual(left, right Entry) bool {
+	return left.PostedTime.Equal(right.PostedTime) &&
+		left.ActualTime.Equal(right.ActualTime) &&
+		left.Amount.Equal(right.Amount) &&
+		left.Merchant == right.Merchant &&
+		left.Comment == right.Comment &&
+		left.RecordID == right.RecordID
+}
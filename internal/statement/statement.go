@@ -0,0 +1,36 @@
+/*
+ * Copyright © 2026 Martin Strobel
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package statement reads the transaction listings that financial institutions offer for download, so that they can
+// be turned into baronial transactions without being typed in by hand.
+package statement
+
+import (
+	"time"
+
+	"github.com/marstr/envelopes"
+)
+
+// Entry is a single line item from a bank statement.
+type Entry struct {
+	PostedTime time.Time
+	ActualTime time.Time
+	Amount     envelopes.Balance
+	Merchant   string
+	Comment    string
+	RecordID   envelopes.BankRecordID
+}